@@ -18,14 +18,24 @@ type DropboxAuthenticator struct {
 	AppName    string
 	AppKey     string
 	AppSecret  string
+
+	// Use loopback redirect with PKCE instead of copy & paste the code.
+	Loopback     bool
+	LoopbackPort int
 }
 
 func (d *DropboxAuthenticator) Authorise() (string, error) {
 	state := uuid.NewV4().String()
 
-	tok, err := d.auth(state)
+	var tok *oauth2.Token
+	var err error
+	if d.Loopback {
+		tok, err = d.authLoopback(state)
+	} else {
+		tok, err = d.auth(state)
+	}
 	if err != nil {
-		fmt.Errorf("Err: %s\n", err)
+		seelog.Errorf("Unable to authorise: %s", err)
 		return "", err
 	}
 	return tok.AccessToken, nil
@@ -64,7 +74,7 @@ func (d *DropboxAuthenticator) codeDialogue(state string) string {
 	fmt.Print("Enter the authorisation code here: ")
 
 	if _, err := fmt.Scan(&code); err != nil {
-		fmt.Errorf("%s\n", err)
+		seelog.Errorf("Unable to read the authorisation code: %s", err)
 		return ""
 	}
 	return code
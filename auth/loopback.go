@@ -0,0 +1,205 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/cihub/seelog"
+	"golang.org/x/oauth2"
+)
+
+const (
+	DEFAULT_LOOPBACK_PORT = 7800
+
+	loopbackCallbackPath = "/callback"
+	loopbackTimeout      = 5 * time.Minute
+)
+
+type loopbackResult struct {
+	code string
+	err  error
+}
+
+// pkceVerifier generates a code verifier and the challenge of PKCE (RFC 7636).
+func pkceVerifier() (verifier, challenge string, err error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", "", err
+	}
+	verifier = base64.RawURLEncoding.EncodeToString(b)
+	h := sha256.Sum256([]byte(verifier))
+	challenge = base64.RawURLEncoding.EncodeToString(h[:])
+	return verifier, challenge, nil
+}
+
+func (d *DropboxAuthenticator) loopbackHandler(state string, results chan<- loopbackResult) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc(loopbackCallbackPath, func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		if q.Get("state") != state {
+			// Not a redirect of this flow. Ignore and keep waiting for the redirect.
+			seelog.Warnf("Ignore the request with mismatched state from %s", r.RemoteAddr)
+			http.Error(w, "State mismatch in the redirect", http.StatusBadRequest)
+			return
+		}
+
+		var result loopbackResult
+		switch {
+		case q.Get("error") != "":
+			result.err = fmt.Errorf("Authorisation denied: %s %s", q.Get("error"), q.Get("error_description"))
+		case q.Get("code") == "":
+			result.err = errors.New("No authorisation code in the redirect")
+		default:
+			result.code = q.Get("code")
+		}
+
+		if result.err != nil {
+			http.Error(w, result.err.Error(), http.StatusBadRequest)
+		} else {
+			fmt.Fprintf(w, "Application '%s' authorised. You can close this window.\n", d.AppName)
+		}
+
+		select {
+		case results <- result:
+		default:
+			// Ignore redirects after the first one
+		}
+	})
+	return mux
+}
+
+func (d *DropboxAuthenticator) authLoopback(state string) (*oauth2.Token, error) {
+	listener, err := net.Listen("tcp", fmt.Sprintf("127.0.0.1:%d", d.LoopbackPort))
+	if err != nil {
+		seelog.Warnf("Unable to start loopback listener, fallback to copy & paste authorisation: %s", err)
+		return d.authPkce(state)
+	}
+	defer listener.Close()
+
+	verifier, challenge, err := pkceVerifier()
+	if err != nil {
+		return nil, err
+	}
+
+	port := listener.Addr().(*net.TCPAddr).Port
+	redirectUri := fmt.Sprintf("http://127.0.0.1:%d%s", port, loopbackCallbackPath)
+
+	results := make(chan loopbackResult, 1)
+	go http.Serve(listener, d.loopbackHandler(state, results))
+
+	cfg := d.authConfig()
+	cfg.RedirectURL = redirectUri
+	authUrl := cfg.AuthCodeURL(
+		state,
+		oauth2.SetAuthURLParam("response_type", "code"),
+		oauth2.SetAuthURLParam("code_challenge", challenge),
+		oauth2.SetAuthURLParam("code_challenge_method", "S256"),
+	)
+
+	seelog.Flush()
+	fmt.Println("=====================")
+	fmt.Printf("Authorise application '%s' with '%s' permission.\n", d.AppName, d.Permission)
+	fmt.Println("1. Visit the URL for the auth dialog:")
+	fmt.Println("")
+	fmt.Println(authUrl)
+	fmt.Println("")
+	fmt.Println("2. Click 'Allow' (you might have to login first)")
+	fmt.Printf("3. Wait for redirect to %s\n", redirectUri)
+
+	select {
+	case r := <-results:
+		if r.err != nil {
+			return nil, r.err
+		}
+		return d.authExchangePkce(cfg, r.code, verifier)
+
+	case <-time.After(loopbackTimeout):
+		return nil, errors.New("Timeout waiting for the authorisation")
+	}
+}
+
+// authPkce authorises with copy & paste of the code, with PKCE.
+func (d *DropboxAuthenticator) authPkce(state string) (*oauth2.Token, error) {
+	verifier, challenge, err := pkceVerifier()
+	if err != nil {
+		return nil, err
+	}
+
+	cfg := d.authConfig()
+	authUrl := cfg.AuthCodeURL(
+		state,
+		oauth2.SetAuthURLParam("response_type", "code"),
+		oauth2.SetAuthURLParam("code_challenge", challenge),
+		oauth2.SetAuthURLParam("code_challenge_method", "S256"),
+	)
+
+	seelog.Flush()
+	fmt.Println("=====================")
+	fmt.Printf("Authorise application '%s' with '%s' permission.\n", d.AppName, d.Permission)
+	fmt.Println("1. Visit the URL for the auth dialog:")
+	fmt.Println("")
+	fmt.Println(authUrl)
+	fmt.Println("")
+	fmt.Println("2. Click 'Allow' (you might have to login first)")
+	fmt.Println("3. Copy the authorisation code: ")
+
+	code := d.codeDialogue(state)
+
+	return d.authExchangePkce(cfg, code, verifier)
+}
+
+// authExchangePkce exchanges the code with the code verifier. The redirect URI and
+// the app secret are sent only if they're configured.
+func (d *DropboxAuthenticator) authExchangePkce(cfg *oauth2.Config, code, verifier string) (*oauth2.Token, error) {
+	values := url.Values{
+		"grant_type":    {"authorization_code"},
+		"code":          {code},
+		"client_id":     {cfg.ClientID},
+		"code_verifier": {verifier},
+	}
+	if cfg.RedirectURL != "" {
+		values.Set("redirect_uri", cfg.RedirectURL)
+	}
+	if cfg.ClientSecret != "" {
+		values.Set("client_secret", cfg.ClientSecret)
+	}
+
+	resp, err := http.Post(cfg.Endpoint.TokenURL, "application/x-www-form-urlencoded", strings.NewReader(values.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("Unable to exchange the authorisation code: %d %s", resp.StatusCode, string(body))
+	}
+
+	tok := &struct {
+		AccessToken string `json:"access_token"`
+		TokenType   string `json:"token_type"`
+	}{}
+	if err := json.Unmarshal(body, tok); err != nil {
+		return nil, err
+	}
+	if tok.AccessToken == "" {
+		return nil, errors.New("No access token in the response")
+	}
+	return &oauth2.Token{
+		AccessToken: tok.AccessToken,
+		TokenType:   tok.TokenType,
+	}, nil
+}
@@ -0,0 +1,44 @@
+package auth
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestLoopbackHandlerIgnoresMismatchedState(t *testing.T) {
+	d := &DropboxAuthenticator{AppName: "test"}
+	results := make(chan loopbackResult, 1)
+	h := d.loopbackHandler("expected", results)
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("GET", loopbackCallbackPath+"?state=other&code=x", nil))
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Unexpected status: %d", w.Code)
+	}
+	select {
+	case r := <-results:
+		t.Errorf("Mismatched state should not finish the flow: %v", r)
+	default:
+	}
+
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("GET", loopbackCallbackPath+"?state=expected&code=abc", nil))
+	if w.Code != http.StatusOK {
+		t.Errorf("Unexpected status: %d", w.Code)
+	}
+	r := <-results
+	if r.err != nil || r.code != "abc" {
+		t.Errorf("Unexpected result: %v", r)
+	}
+}
+
+func TestPkceVerifier(t *testing.T) {
+	verifier, challenge, err := pkceVerifier()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(verifier) < 43 || len(challenge) != 43 {
+		t.Errorf("Unexpected length: verifier[%s] challenge[%s]", verifier, challenge)
+	}
+}
@@ -0,0 +1,96 @@
+package auth
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+var (
+	tokenEnvNames = map[string]string{
+		PERMISSION_INFO:  "DREPORT_TOKEN_INFO",
+		PERMISSION_FILE:  "DREPORT_TOKEN_FILE",
+		PERMISSION_AUDIT: "DREPORT_TOKEN_AUDIT",
+	}
+)
+
+// PresetTokens are pre-issued tokens by permission type.
+type PresetTokens map[string]string
+
+func TokenEnvName(permission string) string {
+	return tokenEnvNames[permission]
+}
+
+func PresetTokensFromEnv() PresetTokens {
+	tokens := make(PresetTokens)
+	for p, e := range tokenEnvNames {
+		if t := strings.TrimSpace(os.Getenv(e)); t != "" {
+			tokens[p] = t
+		}
+	}
+	return tokens
+}
+
+// PresetTokensFromFile reads tokens from the file. Reads from stdin if the path is "-".
+// Each line of the file is formatted as `permission=token` (e.g. `info=xxxx`).
+// Empty lines and lines start with '#' are ignored.
+func PresetTokensFromFile(path string) (PresetTokens, error) {
+	if path == "-" {
+		return parsePresetTokens(os.Stdin)
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return parsePresetTokens(f)
+}
+
+func parsePresetTokens(r io.Reader) (PresetTokens, error) {
+	tokens := make(PresetTokens)
+	scanner := bufio.NewScanner(r)
+	lineNum := 0
+	for scanner.Scan() {
+		lineNum++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		kv := strings.SplitN(line, "=", 2)
+		if len(kv) != 2 {
+			return nil, fmt.Errorf("Invalid token line at line %d", lineNum)
+		}
+		p := strings.TrimSpace(kv[0])
+		if _, ok := tokenEnvNames[p]; !ok {
+			return nil, fmt.Errorf("Unknown permission type '%s' at line %d", p, lineNum)
+		}
+		tokens[p] = strings.TrimSpace(kv[1])
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return tokens, nil
+}
+
+// Merge returns tokens which overrides tokens of this by other.
+func (t PresetTokens) Merge(other PresetTokens) PresetTokens {
+	merged := make(PresetTokens)
+	for p, v := range t {
+		merged[p] = v
+	}
+	for p, v := range other {
+		merged[p] = v
+	}
+	return merged
+}
+
+// IsTerminal returns true if the file is a character device such as TTY.
+func IsTerminal(f *os.File) bool {
+	fi, err := f.Stat()
+	if err != nil {
+		return false
+	}
+	return fi.Mode()&os.ModeCharDevice != 0
+}
@@ -0,0 +1,56 @@
+package auth
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParsePresetTokens(t *testing.T) {
+	content := `
+# comment
+info = token-info
+file=token=with=equals
+
+audit=token-audit
+`
+	tokens, err := parsePresetTokens(strings.NewReader(content))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := map[string]string{
+		PERMISSION_INFO:  "token-info",
+		PERMISSION_FILE:  "token=with=equals",
+		PERMISSION_AUDIT: "token-audit",
+	}
+	if len(tokens) != len(expected) {
+		t.Errorf("Unexpected tokens: %v", tokens)
+	}
+	for p, v := range expected {
+		if tokens[p] != v {
+			t.Errorf("Unexpected token for '%s': '%s'", p, tokens[p])
+		}
+	}
+}
+
+func TestParsePresetTokensInvalid(t *testing.T) {
+	invalids := []string{
+		"info",
+		"unknown=token",
+	}
+	for _, c := range invalids {
+		if _, err := parsePresetTokens(strings.NewReader(c)); err == nil {
+			t.Errorf("Should be an error: '%s'", c)
+		}
+	}
+}
+
+func TestPresetTokensMerge(t *testing.T) {
+	base := PresetTokens{PERMISSION_INFO: "a", PERMISSION_FILE: "b"}
+	merged := base.Merge(PresetTokens{PERMISSION_FILE: "c"})
+	if merged[PERMISSION_INFO] != "a" || merged[PERMISSION_FILE] != "c" {
+		t.Errorf("Unexpected merge result: %v", merged)
+	}
+	if base[PERMISSION_FILE] != "b" {
+		t.Error("Merge should not modify the receiver")
+	}
+}
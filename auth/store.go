@@ -0,0 +1,128 @@
+package auth
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"os/user"
+	"path/filepath"
+	"runtime"
+
+	"github.com/cihub/seelog"
+)
+
+const (
+	DEFAULT_TEAM = "default"
+)
+
+// TokenStore keeps tokens per team and per permission type in a file
+// which is readable only by the owner.
+type TokenStore struct {
+	Path string
+	Team string
+}
+
+type storedTokens struct {
+	// Tokens by team name, then by permission type.
+	Teams map[string]map[string]string `json:"teams"`
+}
+
+func DefaultTokenStorePath() string {
+	var dir string
+	switch {
+	case runtime.GOOS == "windows" && os.Getenv("APPDATA") != "":
+		dir = os.Getenv("APPDATA")
+	case os.Getenv("XDG_CONFIG_HOME") != "":
+		dir = os.Getenv("XDG_CONFIG_HOME")
+	default:
+		home := os.Getenv("HOME")
+		if u, err := user.Current(); err == nil {
+			home = u.HomeDir
+		}
+		dir = filepath.Join(home, ".config")
+	}
+	return filepath.Join(dir, "dreport", "tokens.json")
+}
+
+func (s *TokenStore) team() string {
+	if s.Team == "" {
+		return DEFAULT_TEAM
+	}
+	return s.Team
+}
+
+func (s *TokenStore) load() (*storedTokens, error) {
+	st := &storedTokens{
+		Teams: make(map[string]map[string]string),
+	}
+	content, err := ioutil.ReadFile(s.Path)
+	if os.IsNotExist(err) {
+		return st, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(content, st); err != nil {
+		return nil, err
+	}
+	if st.Teams == nil {
+		st.Teams = make(map[string]map[string]string)
+	}
+	return st, nil
+}
+
+func (s *TokenStore) save(st *storedTokens) error {
+	content, err := json.MarshalIndent(st, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(s.Path), 0700); err != nil {
+		return err
+	}
+	tmp := s.Path + ".tmp"
+	if err := ioutil.WriteFile(tmp, content, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, s.Path)
+}
+
+// Load returns stored token for the permission. Returns empty string if no token stored.
+func (s *TokenStore) Load(permission string) string {
+	st, err := s.load()
+	if err != nil {
+		seelog.Warnf("Unable to load token store '%s': %s", s.Path, err)
+		return ""
+	}
+	if tokens, ok := st.Teams[s.team()]; ok {
+		return tokens[permission]
+	}
+	return ""
+}
+
+func (s *TokenStore) Save(permission, token string) error {
+	st, err := s.load()
+	if err != nil {
+		return err
+	}
+	tokens, ok := st.Teams[s.team()]
+	if !ok {
+		tokens = make(map[string]string)
+		st.Teams[s.team()] = tokens
+	}
+	tokens[permission] = token
+	return s.save(st)
+}
+
+func (s *TokenStore) Delete(permission string) error {
+	st, err := s.load()
+	if err != nil {
+		return err
+	}
+	if tokens, ok := st.Teams[s.team()]; ok {
+		delete(tokens, permission)
+		if len(tokens) == 0 {
+			delete(st.Teams, s.team())
+		}
+	}
+	return s.save(st)
+}
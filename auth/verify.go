@@ -0,0 +1,17 @@
+package auth
+
+import (
+	"github.com/cihub/seelog"
+	"github.com/dropbox/dropbox-sdk-go-unofficial"
+)
+
+// VerifyToken checks the token against the API.
+func VerifyToken(token string) error {
+	client := dropbox.Client(token, dropbox.Options{})
+	info, err := client.GetInfo()
+	if err != nil {
+		return err
+	}
+	seelog.Infof("Token verified for team: %s", info.Name)
+	return nil
+}
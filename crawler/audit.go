@@ -0,0 +1,158 @@
+package crawler
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/cihub/seelog"
+	"github.com/watermint/dreport/integration"
+)
+
+type Tagged struct {
+	Tag string `json:".tag"`
+}
+
+type TeamEventUser struct {
+	Tag          string `json:".tag"`
+	AccountId    string `json:"account_id"`
+	TeamMemberId string `json:"team_member_id"`
+	DisplayName  string `json:"display_name"`
+	Email        string `json:"email"`
+}
+
+type TeamEventApp struct {
+	Tag         string `json:".tag"`
+	AppId       string `json:"app_id"`
+	DisplayName string `json:"display_name"`
+}
+
+type TeamEventActor struct {
+	Tag   string         `json:".tag"`
+	User  *TeamEventUser `json:"user"`
+	Admin *TeamEventUser `json:"admin"`
+	App   *TeamEventApp  `json:"app"`
+}
+
+type TeamEventOrigin struct {
+	GeoLocation *struct {
+		City      string `json:"city"`
+		Region    string `json:"region"`
+		Country   string `json:"country"`
+		IpAddress string `json:"ip_address"`
+	} `json:"geo_location"`
+	AccessMethod *Tagged `json:"access_method"`
+}
+
+type TeamEventAssetPath struct {
+	Contextual        string `json:"contextual"`
+	NamespaceRelative *struct {
+		NsId         string `json:"ns_id"`
+		RelativePath string `json:"relative_path"`
+	} `json:"namespace_relative"`
+}
+
+type TeamEventAsset struct {
+	Tag         string              `json:".tag"`
+	DisplayName string              `json:"display_name"`
+	Path        *TeamEventAssetPath `json:"path"`
+}
+
+type TeamEventType struct {
+	Tag         string `json:".tag"`
+	Description string `json:"description"`
+}
+
+type TeamEvent struct {
+	Timestamp            time.Time        `json:"timestamp"`
+	EventCategory        *Tagged          `json:"event_category"`
+	EventType            *TeamEventType   `json:"event_type"`
+	Actor                *TeamEventActor  `json:"actor"`
+	Origin               *TeamEventOrigin `json:"origin"`
+	InvolveNonTeamMember bool             `json:"involve_non_team_member"`
+	Context              *TeamEventUser   `json:"context"`
+	Assets               []TeamEventAsset `json:"assets"`
+	Details              json.RawMessage  `json:"details"`
+}
+
+type teamEventsTimeRange struct {
+	StartTime string `json:"start_time,omitempty"`
+	EndTime   string `json:"end_time,omitempty"`
+}
+
+type teamEventsArg struct {
+	Limit     uint32               `json:"limit"`
+	Time      *teamEventsTimeRange `json:"time,omitempty"`
+	Category  *Tagged              `json:"category,omitempty"`
+	EventType *Tagged              `json:"event_type,omitempty"`
+}
+
+type teamEventsContinueArg struct {
+	Cursor string `json:"cursor"`
+}
+
+type teamEventsResult struct {
+	Events  []*TeamEvent `json:"events"`
+	Cursor  string       `json:"cursor"`
+	HasMore bool         `json:"has_more"`
+}
+
+// AllTeamEvents pages through the team event log within the period of the context.
+// An empty category loads events of all categories.
+func AllTeamEvents(ctx *integration.ReportContext, category string, handler func(event *TeamEvent) error) error {
+	arg := newTeamEventsArg(ctx)
+	if category != "" {
+		arg.Category = &Tagged{Tag: category}
+	}
+	seelog.Infof("Loading team events: category[%s]", category)
+	return teamEvents(ctx, arg, handler)
+}
+
+// AllTeamEventsOfType pages through events of the event type within the period of the context.
+func AllTeamEventsOfType(ctx *integration.ReportContext, eventType string, handler func(event *TeamEvent) error) error {
+	arg := newTeamEventsArg(ctx)
+	arg.EventType = &Tagged{Tag: eventType}
+	seelog.Infof("Loading team events: event_type[%s]", eventType)
+	return teamEvents(ctx, arg, handler)
+}
+
+func newTeamEventsArg(ctx *integration.ReportContext) *teamEventsArg {
+	arg := &teamEventsArg{
+		Limit: 1000,
+	}
+	if !ctx.Since.IsZero() || !ctx.Until.IsZero() {
+		arg.Time = &teamEventsTimeRange{}
+		if !ctx.Since.IsZero() {
+			arg.Time.StartTime = ctx.Since.UTC().Format(time.RFC3339)
+		}
+		if !ctx.Until.IsZero() {
+			arg.Time.EndTime = ctx.Until.UTC().Format(time.RFC3339)
+		}
+	}
+	return arg
+}
+
+func teamEvents(ctx *integration.ReportContext, arg *teamEventsArg, handler func(event *TeamEvent) error) error {
+	events := &teamEventsResult{}
+	if err := rpc(ctx.TeamAuditToken, "", "team_log/get_events", arg, events); err != nil {
+		seelog.Error("Unable to load team events", err)
+		return err
+	}
+	for {
+		for _, e := range events.Events {
+			if err := handler(e); err != nil {
+				return err
+			}
+		}
+		if !events.HasMore {
+			seelog.Info("Finished loading team events")
+			return nil
+		}
+		seelog.Info("Loading more team events..")
+		cont := &teamEventsContinueArg{Cursor: events.Cursor}
+		events = &teamEventsResult{}
+		if err := rpc(ctx.TeamAuditToken, "", "team_log/get_events/continue", cont, events); err != nil {
+			seelog.Error("Unable to load team events (continue)", err)
+			return err
+		}
+	}
+}
@@ -0,0 +1,62 @@
+package crawler
+
+import (
+	"github.com/cihub/seelog"
+	"github.com/dropbox/dropbox-sdk-go-unofficial"
+	"github.com/dropbox/dropbox-sdk-go-unofficial/team"
+	"github.com/watermint/dreport/integration"
+)
+
+// AllMemberDevices loads web sessions, desktop clients and mobile clients of all members.
+func AllMemberDevices(ctx *integration.ReportContext, handler func(devices *team.MemberDevices) error) error {
+	return ResumeMemberDevices(ctx, "", handler, nil)
+}
+
+// ResumeMemberDevices loads devices of all members from the cursor, or from the
+// beginning if the cursor is empty. pageDone is called with the cursor of the next
+// page after devices of each page are handled, to record the progress.
+func ResumeMemberDevices(ctx *integration.ReportContext, cursor string, handler func(devices *team.MemberDevices) error, pageDone func(cursor string) error) error {
+	client := dropbox.Client(ctx.TeamFileToken, dropbox.Options{})
+
+	query := func(cursor string) *team.ListMembersDevicesArg {
+		q := team.NewListMembersDevicesArg()
+		q.Cursor = cursor
+		q.IncludeDesktopClients = true
+		q.IncludeMobileClients = true
+		q.IncludeWebSessions = true
+		return q
+	}
+
+	if cursor == "" {
+		seelog.Info("Loading sessions")
+	} else {
+		seelog.Info("Resume loading sessions")
+	}
+	sessions, err := client.DevicesListMembersDevices(query(cursor))
+	if err != nil {
+		seelog.Error("Unable to load members sessions", err)
+		return err
+	}
+	for {
+		for _, d := range sessions.Devices {
+			if err := handler(d); err != nil {
+				return err
+			}
+		}
+		if !sessions.HasMore {
+			seelog.Info("Finished loading sessions")
+			return nil
+		}
+		if pageDone != nil {
+			if err := pageDone(sessions.Cursor); err != nil {
+				return err
+			}
+		}
+		seelog.Info("Loading more sessions..")
+		sessions, err = client.DevicesListMembersDevices(query(sessions.Cursor))
+		if err != nil {
+			seelog.Error("Unable to load members sessions (continue)", err)
+			return err
+		}
+	}
+}
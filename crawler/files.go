@@ -0,0 +1,91 @@
+package crawler
+
+import (
+	"time"
+
+	"github.com/cihub/seelog"
+)
+
+type FileSharingInfo struct {
+	ReadOnly             bool   `json:"read_only"`
+	ParentSharedFolderId string `json:"parent_shared_folder_id"`
+	SharedFolderId       string `json:"shared_folder_id"`
+}
+
+// FileEntry is an entry of the folder. Tag is one of 'file', 'folder' or 'deleted'.
+type FileEntry struct {
+	Tag            string           `json:".tag"`
+	Name           string           `json:"name"`
+	Id             string           `json:"id"`
+	PathLower      string           `json:"path_lower"`
+	PathDisplay    string           `json:"path_display"`
+	ClientModified time.Time        `json:"client_modified"`
+	ServerModified time.Time        `json:"server_modified"`
+	Rev            string           `json:"rev"`
+	Size           uint64           `json:"size"`
+	ContentHash    string           `json:"content_hash"`
+	SharingInfo    *FileSharingInfo `json:"sharing_info"`
+}
+
+type listFolderArg struct {
+	Path      string `json:"path"`
+	Recursive bool   `json:"recursive"`
+	Limit     uint32 `json:"limit,omitempty"`
+}
+
+type listFolderContinueArg struct {
+	Cursor string `json:"cursor"`
+}
+
+type listFolderResult struct {
+	Entries []*FileEntry `json:"entries"`
+	Cursor  string       `json:"cursor"`
+	HasMore bool         `json:"has_more"`
+}
+
+// ListFolderPages recursively lists entries under the path, and calls the handler
+// for each page with the cursor of the next page. Listing starts from the cursor
+// instead of the path if the cursor is not empty, to resume the listing.
+// Use the path 'ns:<namespace id>' to list the shared folder.
+func ListFolderPages(token, asMemberId, path, cursor string, handler func(entries []*FileEntry, cursor string) error) error {
+	return listFolderPages(token, selectUserHeader, asMemberId, path, cursor, handler)
+}
+
+// ListTeamFolderPages is ListFolderPages of the team folder as the team admin.
+func ListTeamFolderPages(token, adminId, teamFolderId, cursor string, handler func(entries []*FileEntry, cursor string) error) error {
+	return listFolderPages(token, selectAdminHeader, adminId, "ns:"+teamFolderId, cursor, handler)
+}
+
+func listFolderPages(token, selectHeader, asMemberId, path, cursor string, handler func(entries []*FileEntry, cursor string) error) error {
+	res := &listFolderResult{}
+	if cursor == "" {
+		arg := &listFolderArg{
+			Path:      path,
+			Recursive: true,
+			Limit:     2000,
+		}
+		if err := rpcAs(token, selectHeader, asMemberId, "files/list_folder", arg, res); err != nil {
+			seelog.Errorf("Unable to list folder '%s' of member '%s'", path, asMemberId)
+			return err
+		}
+	} else {
+		if err := rpcAs(token, selectHeader, asMemberId, "files/list_folder/continue", &listFolderContinueArg{Cursor: cursor}, res); err != nil {
+			seelog.Errorf("Unable to list folder '%s' of member '%s' (continue)", path, asMemberId)
+			return err
+		}
+	}
+	for {
+		if err := handler(res.Entries, res.Cursor); err != nil {
+			return err
+		}
+		if !res.HasMore {
+			return nil
+		}
+		cont := &listFolderContinueArg{Cursor: res.Cursor}
+		res = &listFolderResult{}
+		if err := rpcAs(token, selectHeader, asMemberId, "files/list_folder/continue", cont, res); err != nil {
+			seelog.Errorf("Unable to list folder '%s' of member '%s' (continue)", path, asMemberId)
+			return err
+		}
+	}
+}
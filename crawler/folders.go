@@ -4,11 +4,31 @@ import (
 	"github.com/dropbox/dropbox-sdk-go-unofficial"
 	"github.com/dropbox/dropbox-sdk-go-unofficial/sharing"
 	"github.com/cihub/seelog"
+	"github.com/watermint/dreport/integration"
 )
 
 func AllSharedFolders(client dropbox.Api) ([]*sharing.SharedFolderMetadata, error) {
+	return ResumeSharedFolders(client, nil, "")
+}
+
+type sharedFoldersProgress struct {
+	Cursor  string                          `json:"cursor"`
+	Folders []*sharing.SharedFolderMetadata `json:"folders"`
+}
+
+// ResumeSharedFolders loads shared folders as AllSharedFolders, with recording the
+// cursor and folders loaded so far under the key of the checkpoint.
+func ResumeSharedFolders(client dropbox.Api, cp *integration.Checkpoint, key string) ([]*sharing.SharedFolderMetadata, error) {
 	folders := make([]*sharing.SharedFolderMetadata, 0)
-	list, err := client.ListFolders(sharing.NewListFoldersArgs())
+	var list *sharing.ListFoldersResult
+	var err error
+	progress := &sharedFoldersProgress{}
+	if cp.Get(key, progress) {
+		folders = progress.Folders
+		list, err = client.ListFoldersContinue(sharing.NewListFoldersContinueArg(progress.Cursor))
+	} else {
+		list, err = client.ListFolders(sharing.NewListFoldersArgs())
+	}
 	if err != nil {
 		seelog.Error("Unable to load shared folders", err)
 		return nil, err
@@ -18,6 +38,10 @@ func AllSharedFolders(client dropbox.Api) ([]*sharing.SharedFolderMetadata, erro
 		if list.Cursor == "" {
 			return folders, nil
 		}
+		cp.Set(key, &sharedFoldersProgress{
+			Cursor:  list.Cursor,
+			Folders: folders,
+		})
 		list, err = client.ListFoldersContinue(sharing.NewListFoldersContinueArg(list.Cursor))
 		if err != nil {
 			seelog.Error("Unable to load shared folders (continue)", err)
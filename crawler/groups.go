@@ -0,0 +1,61 @@
+package crawler
+
+import (
+	"github.com/cihub/seelog"
+	"github.com/dropbox/dropbox-sdk-go-unofficial"
+	"github.com/dropbox/dropbox-sdk-go-unofficial/team"
+	"github.com/dropbox/dropbox-sdk-go-unofficial/team_common"
+	"github.com/watermint/dreport/integration"
+)
+
+func AllGroups(ctx *integration.ReportContext) ([]*team_common.GroupSummary, error) {
+	groupList := make([]*team_common.GroupSummary, 0)
+	client := dropbox.Client(ctx.TeamInfoToken, dropbox.Options{})
+
+	seelog.Info("Loading groups")
+	groups, err := client.GroupsList(team.NewGroupsListArg())
+	if err != nil {
+		seelog.Error("Unable to load group list", err)
+		return nil, err
+	}
+	for {
+		groupList = append(groupList, groups.Groups...)
+		if !groups.HasMore {
+			seelog.Info("Finished loading group list")
+			return groupList, nil
+		}
+		seelog.Info("Loading more groups..")
+		groups, err = client.GroupsListContinue(team.NewGroupsListContinueArg(groups.Cursor))
+		if err != nil {
+			seelog.Error("Unable to load group list (continue)", err)
+			return nil, err
+		}
+	}
+}
+
+func AllGroupMembers(ctx *integration.ReportContext, groupId string) ([]*team.GroupMemberInfo, error) {
+	memberList := make([]*team.GroupMemberInfo, 0)
+	client := dropbox.Client(ctx.TeamInfoToken, dropbox.Options{})
+
+	selector := &team.GroupSelector{
+		GroupId: groupId,
+	}
+	selector.Tag = "group_id"
+
+	members, err := client.GroupsMembersList(team.NewGroupsMembersListArg(selector))
+	if err != nil {
+		seelog.Error("Unable to load group members for group id: "+groupId, err)
+		return nil, err
+	}
+	for {
+		memberList = append(memberList, members.Members...)
+		if !members.HasMore {
+			return memberList, nil
+		}
+		members, err = client.GroupsMembersListContinue(team.NewGroupsMembersListContinueArg(members.Cursor))
+		if err != nil {
+			seelog.Error("Unable to load group members (continue) for group id: "+groupId, err)
+			return nil, err
+		}
+	}
+}
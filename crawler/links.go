@@ -0,0 +1,52 @@
+package crawler
+
+import (
+	"time"
+
+	"github.com/cihub/seelog"
+)
+
+type LinkPermissions struct {
+	ResolvedVisibility  *Tagged `json:"resolved_visibility"`
+	RequestedVisibility *Tagged `json:"requested_visibility"`
+	CanRevoke           bool    `json:"can_revoke"`
+}
+
+// SharedLink is a shared link of the file or the folder. Tag is 'file' or 'folder'.
+type SharedLink struct {
+	Tag             string           `json:".tag"`
+	Url             string           `json:"url"`
+	Name            string           `json:"name"`
+	Id              string           `json:"id"`
+	PathLower       string           `json:"path_lower"`
+	Expires         *time.Time       `json:"expires"`
+	LinkPermissions *LinkPermissions `json:"link_permissions"`
+}
+
+type listSharedLinksArg struct {
+	Cursor string `json:"cursor,omitempty"`
+}
+
+type listSharedLinksResult struct {
+	Links   []*SharedLink `json:"links"`
+	Cursor  string        `json:"cursor"`
+	HasMore bool          `json:"has_more"`
+}
+
+// AllSharedLinks loads all shared links created by the member.
+func AllSharedLinks(token, asMemberId string) ([]*SharedLink, error) {
+	links := make([]*SharedLink, 0)
+	arg := &listSharedLinksArg{}
+	for {
+		res := &listSharedLinksResult{}
+		if err := rpc(token, asMemberId, "sharing/list_shared_links", arg, res); err != nil {
+			seelog.Errorf("Unable to load shared links of member '%s'", asMemberId)
+			return nil, err
+		}
+		links = append(links, res.Links...)
+		if !res.HasMore {
+			return links, nil
+		}
+		arg = &listSharedLinksArg{Cursor: res.Cursor}
+	}
+}
@@ -1,35 +1,89 @@
 package crawler
 
 import (
+	"encoding/json"
 	"github.com/cihub/seelog"
 	"github.com/dropbox/dropbox-sdk-go-unofficial"
 	"github.com/dropbox/dropbox-sdk-go-unofficial/team"
 	"github.com/watermint/dreport/integration"
+	"time"
 )
 
-func AllTeamMembers(ctx *integration.ReportContext) ([]*team.TeamMemberInfo, error) {
+func NewMemberDirectory(ctx *integration.ReportContext, cacheFile string, cacheTTL time.Duration) *integration.MemberDirectory {
+	return &integration.MemberDirectory{
+		Loader: func() ([]*team.TeamMemberInfo, map[string]json.RawMessage, error) {
+			return AllTeamMembers(ctx)
+		},
+		CacheFile: cacheFile,
+		CacheTTL:  cacheTTL,
+		TeamId: func() (string, error) {
+			client := dropbox.Client(ctx.TeamInfoToken, dropbox.Options{})
+			info, err := client.GetInfo()
+			if err != nil {
+				return "", err
+			}
+			return info.TeamId, nil
+		},
+	}
+}
+
+type membersListArg struct {
+	Limit uint32 `json:"limit"`
+}
+
+type membersListContinueArg struct {
+	Cursor string `json:"cursor"`
+}
+
+// Members are decoded twice, into the SDK type and the raw profile.
+type membersListResult struct {
+	Members []json.RawMessage `json:"members"`
+	Cursor  string            `json:"cursor"`
+	HasMore bool              `json:"has_more"`
+}
+
+type rawTeamMemberInfo struct {
+	Profile json.RawMessage `json:"profile"`
+}
+
+// AllTeamMembers loads all team members, with raw profiles keyed by team member id.
+// The member list is not recorded into the checkpoint, and is loaded again on resume
+// (or from the member cache).
+func AllTeamMembers(ctx *integration.ReportContext) ([]*team.TeamMemberInfo, map[string]json.RawMessage, error) {
 	memberList := make([]*team.TeamMemberInfo, 0, 0)
-	client := dropbox.Client(ctx.TeamInfoToken, dropbox.Options{})
+	profiles := make(map[string]json.RawMessage)
 
 	seelog.Info("Loading members")
-	members, err := client.MembersList(team.NewMembersListArg())
-	if err != nil {
+	members := &membersListResult{}
+	if err := rpc(ctx.TeamInfoToken, "", "team/members/list", &membersListArg{Limit: 1000}, members); err != nil {
 		seelog.Error("Unable to load member list", err)
-		return memberList, err
+		return memberList, profiles, err
 	}
 	for {
-		memberList = append(memberList, members.Members...)
+		for _, raw := range members.Members {
+			m := &team.TeamMemberInfo{}
+			if err := json.Unmarshal(raw, m); err != nil {
+				seelog.Error("Unable to parse member", err)
+				return memberList, profiles, err
+			}
+			r := &rawTeamMemberInfo{}
+			if err := json.Unmarshal(raw, r); err != nil {
+				seelog.Error("Unable to parse member", err)
+				return memberList, profiles, err
+			}
+			memberList = append(memberList, m)
+			profiles[m.Profile.TeamMemberId] = r.Profile
+		}
 		if !members.HasMore {
 			seelog.Info("Finished loading member list")
-			return memberList, nil
+			return memberList, profiles, nil
 		}
 		seelog.Info("Loading more members..")
-		cont := team.NewMembersListContinueArg(members.Cursor)
-		members, err = client.MembersListContinue(cont)
-		if err != nil {
+		cont := &membersListContinueArg{Cursor: members.Cursor}
+		members = &membersListResult{}
+		if err := rpc(ctx.TeamInfoToken, "", "team/members/list/continue", cont, members); err != nil {
 			seelog.Error("Unable to load member (continue)", err)
-			return memberList, err
+			return memberList, profiles, err
 		}
 	}
-
 }
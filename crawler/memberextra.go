@@ -0,0 +1,43 @@
+package crawler
+
+import (
+	"encoding/json"
+
+	"github.com/cihub/seelog"
+	"github.com/watermint/dreport/integration"
+)
+
+type SecondaryEmail struct {
+	Email      string `json:"email"`
+	IsVerified bool   `json:"is_verified"`
+}
+
+// TeamMemberExtra is attributes of the member profile which are not covered by the SDK.
+type TeamMemberExtra struct {
+	TeamMemberId    string            `json:"team_member_id"`
+	MemberFolderId  string            `json:"member_folder_id"`
+	SecondaryEmails []*SecondaryEmail `json:"secondary_emails"`
+}
+
+// AllTeamMemberExtras decodes extra attributes of all members from raw profiles of
+// the member directory, keyed by team member id.
+func AllTeamMemberExtras(ctx *integration.ReportContext) (map[string]*TeamMemberExtra, error) {
+	members, err := ctx.Members.Members()
+	if err != nil {
+		return nil, err
+	}
+	extras := make(map[string]*TeamMemberExtra)
+	for _, m := range members {
+		profile, found := ctx.Members.RawProfile(m.Profile.TeamMemberId)
+		if !found {
+			continue
+		}
+		extra := &TeamMemberExtra{}
+		if err := json.Unmarshal(profile, extra); err != nil {
+			seelog.Warnf("Unable to parse attributes of member '%s': %s", m.Profile.TeamMemberId, err)
+			continue
+		}
+		extras[m.Profile.TeamMemberId] = extra
+	}
+	return extras, nil
+}
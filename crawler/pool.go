@@ -0,0 +1,57 @@
+package crawler
+
+type poolResult struct {
+	value interface{}
+	err   error
+}
+
+// ForEachOrdered calls work for each index in [0, n) with up to `concurrency` workers.
+// Results are passed to emit in the order of the index, and emit is called only from
+// the calling goroutine. So emit can write rows without synchronisation.
+// Workers run ahead of the next index to emit by up to 2x concurrency items, so
+// results of a slow item do not pile up without bound.
+// Stops dispatching further work and returns the error if emit returns an error.
+func ForEachOrdered(n, concurrency int, work func(i int) (interface{}, error), emit func(i int, value interface{}, err error) error) error {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	results := make([]chan poolResult, n)
+	for i := range results {
+		results[i] = make(chan poolResult, 1)
+	}
+
+	workers := make(chan struct{}, concurrency)
+	pending := make(chan struct{}, 2*concurrency)
+	done := make(chan struct{})
+	defer close(done)
+
+	go func() {
+		for i := 0; i < n; i++ {
+			select {
+			case pending <- struct{}{}:
+			case <-done:
+				return
+			}
+			select {
+			case workers <- struct{}{}:
+			case <-done:
+				return
+			}
+			go func(i int) {
+				v, err := work(i)
+				<-workers
+				results[i] <- poolResult{value: v, err: err}
+			}(i)
+		}
+	}()
+
+	for i := 0; i < n; i++ {
+		r := <-results[i]
+		if err := emit(i, r.value, r.err); err != nil {
+			return err
+		}
+		<-pending
+	}
+	return nil
+}
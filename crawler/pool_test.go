@@ -0,0 +1,104 @@
+package crawler
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestForEachOrdered(t *testing.T) {
+	n := 50
+	emitted := make([]int, 0, n)
+	err := ForEachOrdered(n, 4, func(i int) (interface{}, error) {
+		// later items finish earlier
+		time.Sleep(time.Duration(n-i) * 100 * time.Microsecond)
+		if i%10 == 0 {
+			return nil, errors.New("failure")
+		}
+		return i * 2, nil
+	}, func(i int, v interface{}, err error) error {
+		if i%10 == 0 {
+			if err == nil {
+				t.Errorf("Error should be passed for %d", i)
+			}
+		} else if err != nil || v.(int) != i*2 {
+			t.Errorf("Unexpected result for %d: %v %v", i, v, err)
+		}
+		emitted = append(emitted, i)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(emitted) != n {
+		t.Fatalf("Unexpected number of results: %d", len(emitted))
+	}
+	for i, v := range emitted {
+		if v != i {
+			t.Fatalf("Unexpected order: %v", emitted)
+		}
+	}
+}
+
+func TestForEachOrderedStopsOnEmitError(t *testing.T) {
+	var mutex sync.Mutex
+	started := 0
+	failure := errors.New("failure")
+	err := ForEachOrdered(1000, 2, func(i int) (interface{}, error) {
+		mutex.Lock()
+		started++
+		mutex.Unlock()
+		return i, nil
+	}, func(i int, v interface{}, err error) error {
+		if i == 3 {
+			return failure
+		}
+		return nil
+	})
+	if err != failure {
+		t.Errorf("Unexpected error: %v", err)
+	}
+	time.Sleep(10 * time.Millisecond)
+	mutex.Lock()
+	defer mutex.Unlock()
+	if started > 3+1+2*2 {
+		t.Errorf("Should stop dispatching: %d started", started)
+	}
+}
+
+func TestForEachOrderedBoundsLookAhead(t *testing.T) {
+	concurrency := 2
+	release := make(chan struct{})
+	var mutex sync.Mutex
+	maxStarted := 0
+	finished := make(chan error)
+	go func() {
+		finished <- ForEachOrdered(100, concurrency, func(i int) (interface{}, error) {
+			mutex.Lock()
+			if i > maxStarted {
+				maxStarted = i
+			}
+			mutex.Unlock()
+			if i == 0 {
+				// slow first item
+				<-release
+			}
+			return i, nil
+		}, func(i int, v interface{}, err error) error {
+			return nil
+		})
+	}()
+
+	time.Sleep(20 * time.Millisecond)
+	mutex.Lock()
+	if maxStarted >= 2*concurrency {
+		t.Errorf("Workers should not run ahead more than %d items: %d", 2*concurrency, maxStarted)
+	}
+	mutex.Unlock()
+
+	close(release)
+	if err := <-finished; err != nil {
+		t.Error(err)
+	}
+}
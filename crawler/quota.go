@@ -0,0 +1,60 @@
+package crawler
+
+import (
+	"github.com/cihub/seelog"
+	"github.com/watermint/dreport/integration"
+)
+
+const (
+	customQuotaBatchSize = 1000
+)
+
+type customQuotaUser struct {
+	Tag          string `json:".tag"`
+	TeamMemberId string `json:"team_member_id"`
+}
+
+type customQuotaArg struct {
+	Users []*customQuotaUser `json:"users"`
+}
+
+type customQuotaResult struct {
+	Tag     string           `json:".tag"`
+	User    *customQuotaUser `json:"user"`
+	QuotaGb uint32           `json:"quota_gb"`
+}
+
+// CustomQuotas loads custom quota (in GB) of members. Members without custom quota
+// are not included in the result.
+func CustomQuotas(ctx *integration.ReportContext, teamMemberIds []string) (map[string]uint32, error) {
+	quotas := make(map[string]uint32)
+
+	seelog.Info("Loading custom quota of members")
+	for offset := 0; offset < len(teamMemberIds); offset += customQuotaBatchSize {
+		end := offset + customQuotaBatchSize
+		if end > len(teamMemberIds) {
+			end = len(teamMemberIds)
+		}
+		arg := &customQuotaArg{
+			Users: make([]*customQuotaUser, 0, end-offset),
+		}
+		for _, id := range teamMemberIds[offset:end] {
+			arg.Users = append(arg.Users, &customQuotaUser{
+				Tag:          "team_member_id",
+				TeamMemberId: id,
+			})
+		}
+
+		res := make([]*customQuotaResult, 0)
+		if err := rpc(ctx.TeamInfoToken, "", "team/member_space_limits/get_custom_quota", arg, &res); err != nil {
+			seelog.Error("Unable to load custom quota", err)
+			return nil, err
+		}
+		for _, r := range res {
+			if r.Tag == "success" && r.User != nil {
+				quotas[r.User.TeamMemberId] = r.QuotaGb
+			}
+		}
+	}
+	return quotas, nil
+}
@@ -0,0 +1,79 @@
+package crawler
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+)
+
+const (
+	rpcEndpoint = "https://api.dropboxapi.com/2/"
+
+	selectUserHeader  = "Dropbox-API-Select-User"
+	selectAdminHeader = "Dropbox-API-Select-Admin"
+)
+
+type RpcError struct {
+	Route        string
+	StatusCode   int
+	ErrorSummary string
+}
+
+func (e *RpcError) Error() string {
+	return fmt.Sprintf("%s: %d %s", e.Route, e.StatusCode, e.ErrorSummary)
+}
+
+// rpc calls RPC style endpoints which are not covered by the SDK.
+func rpc(token, asMemberId, route string, arg interface{}, res interface{}) error {
+	return rpcAs(token, selectUserHeader, asMemberId, route, arg, res)
+}
+
+// rpcAsAdmin calls the endpoint as the team admin. Unlike calls as the member, the
+// admin can access team folders which the admin is not a member of.
+func rpcAsAdmin(token, adminId, route string, arg interface{}, res interface{}) error {
+	return rpcAs(token, selectAdminHeader, adminId, route, arg, res)
+}
+
+func rpcAs(token, selectHeader, teamMemberId, route string, arg interface{}, res interface{}) error {
+	body, err := json.Marshal(arg)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest("POST", rpcEndpoint+route, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	req.Header.Set("Content-Type", "application/json")
+	if teamMemberId != "" {
+		req.Header.Set(selectHeader, teamMemberId)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	respBody, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != http.StatusOK {
+		rpcErr := &RpcError{
+			Route:        route,
+			StatusCode:   resp.StatusCode,
+			ErrorSummary: string(respBody),
+		}
+		apiErr := struct {
+			ErrorSummary string `json:"error_summary"`
+		}{}
+		if json.Unmarshal(respBody, &apiErr) == nil && apiErr.ErrorSummary != "" {
+			rpcErr.ErrorSummary = apiErr.ErrorSummary
+		}
+		return rpcErr
+	}
+	return json.Unmarshal(respBody, res)
+}
@@ -0,0 +1,108 @@
+package crawler
+
+import (
+	"github.com/cihub/seelog"
+	"github.com/watermint/dreport/integration"
+)
+
+const (
+	// StatsDateLayout is the layout of dates of the statistics API.
+	StatsDateLayout = "2006-01-02"
+)
+
+// Statistics are arrays of daily values from the start date. Values are nil
+// if the value is not available for the day.
+type StorageStats struct {
+	StartDate     string    `json:"start_date"`
+	TotalUsage    []*uint64 `json:"total_usage"`
+	SharedUsage   []*uint64 `json:"shared_usage"`
+	UnsharedUsage []*uint64 `json:"unshared_usage"`
+	SharedFolders []*uint64 `json:"shared_folders"`
+}
+
+type ActivityStats struct {
+	StartDate                      string    `json:"start_date"`
+	Adds                           []*uint64 `json:"adds"`
+	Edits                          []*uint64 `json:"edits"`
+	Deletes                        []*uint64 `json:"deletes"`
+	ActiveUsers28Day               []*uint64 `json:"active_users_28_day"`
+	ActiveUsers7Day                []*uint64 `json:"active_users_7_day"`
+	ActiveUsers1Day                []*uint64 `json:"active_users_1_day"`
+	ActiveSharedFolders28Day       []*uint64 `json:"active_shared_folders_28_day"`
+	ActiveSharedFolders7Day        []*uint64 `json:"active_shared_folders_7_day"`
+	ActiveSharedFolders1Day        []*uint64 `json:"active_shared_folders_1_day"`
+	SharedLinksCreated             []*uint64 `json:"shared_links_created"`
+	SharedLinksViewedByTeam        []*uint64 `json:"shared_links_viewed_by_team"`
+	SharedLinksViewedByOutsideUser []*uint64 `json:"shared_links_viewed_by_outside_user"`
+	SharedLinksViewedByNotLoggedIn []*uint64 `json:"shared_links_viewed_by_not_logged_in"`
+	SharedLinksViewedTotal         []*uint64 `json:"shared_links_viewed_total"`
+}
+
+type DevicesActive struct {
+	Windows []*uint64 `json:"windows"`
+	Macos   []*uint64 `json:"macos"`
+	Linux   []*uint64 `json:"linux"`
+	Ios     []*uint64 `json:"ios"`
+	Android []*uint64 `json:"android"`
+	Other   []*uint64 `json:"other"`
+	Total   []*uint64 `json:"total"`
+}
+
+type DevicesStats struct {
+	StartDate   string         `json:"start_date"`
+	Active1Day  *DevicesActive `json:"active_1_day"`
+	Active7Day  *DevicesActive `json:"active_7_day"`
+	Active28Day *DevicesActive `json:"active_28_day"`
+}
+
+type MembershipStats struct {
+	StartDate        string    `json:"start_date"`
+	TeamSize         []*uint64 `json:"team_size"`
+	PendingInvites   []*uint64 `json:"pending_invites"`
+	MembersJoined    []*uint64 `json:"members_joined"`
+	SuspendedMembers []*uint64 `json:"suspended_members"`
+	Licenses         []*uint64 `json:"licenses"`
+}
+
+type statsDateRange struct {
+	StartDate string `json:"start_date,omitempty"`
+	EndDate   string `json:"end_date,omitempty"`
+}
+
+// Load statistics of the period of the context. The end date is exclusive.
+func teamStats(ctx *integration.ReportContext, route string, res interface{}) error {
+	arg := &statsDateRange{}
+	if !ctx.Since.IsZero() {
+		arg.StartDate = ctx.Since.Format(StatsDateLayout)
+	}
+	if !ctx.Until.IsZero() {
+		arg.EndDate = ctx.Until.Format(StatsDateLayout)
+	}
+
+	seelog.Infof("Loading team statistics: %s", route)
+	if err := rpc(ctx.TeamInfoToken, "", route, arg, res); err != nil {
+		seelog.Errorf("Unable to load team statistics: %s", route)
+		return err
+	}
+	return nil
+}
+
+func TeamStorageStats(ctx *integration.ReportContext) (*StorageStats, error) {
+	res := &StorageStats{}
+	return res, teamStats(ctx, "team/reports/get_storage", res)
+}
+
+func TeamActivityStats(ctx *integration.ReportContext) (*ActivityStats, error) {
+	res := &ActivityStats{}
+	return res, teamStats(ctx, "team/reports/get_activity", res)
+}
+
+func TeamDevicesStats(ctx *integration.ReportContext) (*DevicesStats, error) {
+	res := &DevicesStats{}
+	return res, teamStats(ctx, "team/reports/get_devices", res)
+}
+
+func TeamMembershipStats(ctx *integration.ReportContext) (*MembershipStats, error) {
+	res := &MembershipStats{}
+	return res, teamStats(ctx, "team/reports/get_membership", res)
+}
@@ -0,0 +1,105 @@
+package crawler
+
+import (
+	"github.com/cihub/seelog"
+	"github.com/dropbox/dropbox-sdk-go-unofficial/sharing"
+	"github.com/watermint/dreport/integration"
+)
+
+type TeamFolderContentSyncSetting struct {
+	Id          string  `json:"id"`
+	SyncSetting *Tagged `json:"sync_setting"`
+}
+
+type TeamFolder struct {
+	TeamFolderId        string                          `json:"team_folder_id"`
+	Name                string                          `json:"name"`
+	Status              *Tagged                         `json:"status"`
+	IsTeamSharedDropbox bool                            `json:"is_team_shared_dropbox"`
+	SyncSetting         *Tagged                         `json:"sync_setting"`
+	ContentSyncSettings []*TeamFolderContentSyncSetting `json:"content_sync_settings"`
+}
+
+type teamFolderListArg struct {
+	Limit uint32 `json:"limit"`
+}
+
+type teamFolderListContinueArg struct {
+	Cursor string `json:"cursor"`
+}
+
+type teamFolderListResult struct {
+	TeamFolders []*TeamFolder `json:"team_folders"`
+	Cursor      string        `json:"cursor"`
+	HasMore     bool          `json:"has_more"`
+}
+
+type teamFolderMembersArg struct {
+	SharedFolderId string `json:"shared_folder_id"`
+}
+
+type teamFolderMembersContinueArg struct {
+	Cursor string `json:"cursor"`
+}
+
+type teamFolderMembersResult struct {
+	Users    []*sharing.UserMembershipInfo    `json:"users"`
+	Groups   []*sharing.GroupMembershipInfo   `json:"groups"`
+	Invitees []*sharing.InviteeMembershipInfo `json:"invitees"`
+	Cursor   string                           `json:"cursor"`
+}
+
+// AllTeamFolders loads all team folders of the team, including archived team folders.
+func AllTeamFolders(ctx *integration.ReportContext) ([]*TeamFolder, error) {
+	teamFolders := make([]*TeamFolder, 0)
+
+	seelog.Info("Loading team folders")
+	res := &teamFolderListResult{}
+	if err := rpc(ctx.TeamFileToken, "", "team/team_folder/list", &teamFolderListArg{Limit: 1000}, res); err != nil {
+		seelog.Error("Unable to load team folders", err)
+		return nil, err
+	}
+	for {
+		teamFolders = append(teamFolders, res.TeamFolders...)
+		if !res.HasMore {
+			seelog.Info("Finished loading team folders")
+			return teamFolders, nil
+		}
+		seelog.Info("Loading more team folders..")
+		cont := &teamFolderListContinueArg{Cursor: res.Cursor}
+		res = &teamFolderListResult{}
+		if err := rpc(ctx.TeamFileToken, "", "team/team_folder/list/continue", cont, res); err != nil {
+			seelog.Error("Unable to load team folders (continue)", err)
+			return nil, err
+		}
+	}
+}
+
+// AllTeamFolderMembers loads members of the team folder as the team admin, thus
+// members are loaded even if the admin is not a member of the team folder.
+func AllTeamFolderMembers(ctx *integration.ReportContext, adminId, teamFolderId string) ([]*sharing.GroupMembershipInfo, []*sharing.UserMembershipInfo, []*sharing.InviteeMembershipInfo, error) {
+	groups := make([]*sharing.GroupMembershipInfo, 0)
+	users := make([]*sharing.UserMembershipInfo, 0)
+	invitees := make([]*sharing.InviteeMembershipInfo, 0)
+
+	res := &teamFolderMembersResult{}
+	if err := rpcAsAdmin(ctx.TeamFileToken, adminId, "sharing/list_folder_members", &teamFolderMembersArg{SharedFolderId: teamFolderId}, res); err != nil {
+		seelog.Error("Unable to load team folder members for team folder id: "+teamFolderId, err)
+		return nil, nil, nil, err
+	}
+	for {
+		groups = append(groups, res.Groups...)
+		users = append(users, res.Users...)
+		invitees = append(invitees, res.Invitees...)
+
+		if res.Cursor == "" {
+			return groups, users, invitees, nil
+		}
+		cont := &teamFolderMembersContinueArg{Cursor: res.Cursor}
+		res = &teamFolderMembersResult{}
+		if err := rpcAsAdmin(ctx.TeamFileToken, adminId, "sharing/list_folder_members/continue", cont, res); err != nil {
+			seelog.Error("Unable to load team folder members (continue) for team folder id: "+teamFolderId, err)
+			return nil, nil, nil, err
+		}
+	}
+}
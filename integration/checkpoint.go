@@ -0,0 +1,197 @@
+package integration
+
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/cihub/seelog"
+	"github.com/watermint/dreport/publisher"
+)
+
+const (
+	DEFAULT_CHECKPOINT_INTERVAL = 10 * time.Second
+)
+
+// Checkpoint records progress of the crawl, to resume the report from the point of
+// the interruption. The checkpoint records entities which rows are already written,
+// pagination cursors and report specific state, with the size of the output at
+// that time. Methods are no-op on nil receiver, thus reports can use the
+// checkpoint regardless of whether checkpoint is enabled.
+type Checkpoint struct {
+	Path     string
+	Report   string
+	Output   publisher.Resumable
+	Interval time.Duration
+
+	mutex     sync.Mutex
+	state     *checkpointState
+	lastSaved time.Time
+}
+
+type checkpointState struct {
+	Report string                     `json:"report"`
+	Offset int64                      `json:"offset"`
+	Done   map[string]bool            `json:"done"`
+	Values map[string]json.RawMessage `json:"values"`
+}
+
+func NewCheckpoint(path, report string, output publisher.Resumable) *Checkpoint {
+	return &Checkpoint{
+		Path:     path,
+		Report:   report,
+		Output:   output,
+		Interval: DEFAULT_CHECKPOINT_INTERVAL,
+		state: &checkpointState{
+			Report: report,
+			Done:   make(map[string]bool),
+			Values: make(map[string]json.RawMessage),
+		},
+		lastSaved: time.Now(),
+	}
+}
+
+// Load loads the checkpoint file. Returns false if the file does not exist.
+func (c *Checkpoint) Load() (bool, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	content, err := ioutil.ReadFile(c.Path)
+	if os.IsNotExist(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	state := &checkpointState{}
+	if err := json.Unmarshal(content, state); err != nil {
+		return false, err
+	}
+	if state.Report != c.Report {
+		return false, errors.New("Checkpoint is not created by report: " + c.Report)
+	}
+	if state.Done == nil {
+		state.Done = make(map[string]bool)
+	}
+	if state.Values == nil {
+		state.Values = make(map[string]json.RawMessage)
+	}
+	c.state = state
+	return true, nil
+}
+
+// Offset returns the size of the output at the last save.
+func (c *Checkpoint) Offset() int64 {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.state.Offset
+}
+
+func (c *Checkpoint) IsDone(key string) bool {
+	if c == nil {
+		return false
+	}
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.state.Done[key]
+}
+
+// Done marks the entity as completed. Call this after all rows of the entity are
+// written. The value of the key is discarded.
+func (c *Checkpoint) Done(key string) {
+	if c == nil {
+		return
+	}
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.state.Done[key] = true
+	delete(c.state.Values, key)
+}
+
+// Get loads the value recorded for the key into v. Returns false if no value recorded.
+func (c *Checkpoint) Get(key string, v interface{}) bool {
+	if c == nil {
+		return false
+	}
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	raw, ok := c.state.Values[key]
+	if !ok {
+		return false
+	}
+	if err := json.Unmarshal(raw, v); err != nil {
+		seelog.Warnf("Unable to restore checkpoint value '%s': %s", key, err)
+		return false
+	}
+	return true
+}
+
+// Set records the value such as pagination cursor for the key.
+func (c *Checkpoint) Set(key string, v interface{}) {
+	if c == nil {
+		return
+	}
+	raw, err := json.Marshal(v)
+	if err != nil {
+		seelog.Warnf("Unable to record checkpoint value '%s': %s", key, err)
+		return
+	}
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.state.Values[key] = raw
+}
+
+// Commit saves the checkpoint if the interval elapsed since the last save.
+// Call this only from the goroutine which writes rows.
+func (c *Checkpoint) Commit() error {
+	if c == nil {
+		return nil
+	}
+	if time.Now().Sub(c.lastSaved) < c.Interval {
+		return nil
+	}
+	return c.Save()
+}
+
+// Save saves the checkpoint with the current size of the output.
+// Call this only from the goroutine which writes rows.
+func (c *Checkpoint) Save() error {
+	if c == nil {
+		return nil
+	}
+	offset, err := c.Output.Offset()
+	if err != nil {
+		return err
+	}
+
+	c.mutex.Lock()
+	c.state.Offset = offset
+	content, err := json.Marshal(c.state)
+	c.mutex.Unlock()
+	if err != nil {
+		return err
+	}
+
+	tmp := c.Path + ".tmp"
+	if err := ioutil.WriteFile(tmp, content, 0600); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, c.Path); err != nil {
+		return err
+	}
+	c.lastSaved = time.Now()
+	return nil
+}
+
+// Remove removes the checkpoint file after the report completed.
+func (c *Checkpoint) Remove() {
+	if c == nil {
+		return
+	}
+	if err := os.Remove(c.Path); err != nil && !os.IsNotExist(err) {
+		seelog.Warnf("Unable to remove checkpoint '%s': %s", c.Path, err)
+	}
+}
@@ -0,0 +1,99 @@
+package integration
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+type stubResumable struct {
+	offset int64
+}
+
+func (s *stubResumable) Offset() (int64, error) {
+	return s.offset, nil
+}
+
+func (s *stubResumable) Resume(offset int64) error {
+	s.offset = offset
+	return nil
+}
+
+type testCursor struct {
+	Cursor string `json:"cursor"`
+}
+
+func TestCheckpoint(t *testing.T) {
+	dir, err := ioutil.TempDir("", "dreport")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "report.checkpoint")
+	out := &stubResumable{offset: 123}
+	c := NewCheckpoint(path, "member-files", out)
+	c.Set("dbmid:1", &testCursor{Cursor: "c1"})
+	c.Set("dbmid:2", &testCursor{Cursor: "c2"})
+	c.Done("dbmid:2")
+	if err := c.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	loaded := NewCheckpoint(path, "member-files", &stubResumable{})
+	found, err := loaded.Load()
+	if err != nil || !found {
+		t.Fatalf("Checkpoint should be loaded: %t %v", found, err)
+	}
+	if loaded.Offset() != 123 {
+		t.Errorf("Unexpected offset: %d", loaded.Offset())
+	}
+	if loaded.IsDone("dbmid:1") || !loaded.IsDone("dbmid:2") {
+		t.Error("Unexpected done entities")
+	}
+	cursor := &testCursor{}
+	if !loaded.Get("dbmid:1", cursor) || cursor.Cursor != "c1" {
+		t.Errorf("Unexpected cursor: %v", cursor)
+	}
+	if loaded.Get("dbmid:2", cursor) {
+		t.Error("Value of done entity should be discarded")
+	}
+
+	loaded.Remove()
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Error("Checkpoint should be removed")
+	}
+	if found, err := loaded.Load(); found || err != nil {
+		t.Errorf("Missing checkpoint should not be found: %t %v", found, err)
+	}
+}
+
+func TestCheckpointOtherReport(t *testing.T) {
+	dir, err := ioutil.TempDir("", "dreport")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "report.checkpoint")
+	if err := NewCheckpoint(path, "member-files", &stubResumable{}).Save(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := NewCheckpoint(path, "member-quota", &stubResumable{}).Load(); err == nil {
+		t.Error("Checkpoint of other report should be an error")
+	}
+}
+
+func TestCheckpointNil(t *testing.T) {
+	var c *Checkpoint
+	c.Set("key", "value")
+	c.Done("key")
+	if c.IsDone("key") || c.Get("key", new(string)) {
+		t.Error("Nil checkpoint should not record anything")
+	}
+	if err := c.Commit(); err != nil {
+		t.Error(err)
+	}
+	c.Remove()
+}
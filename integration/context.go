@@ -1,6 +1,10 @@
 package integration
 
-import "github.com/watermint/dreport/publisher"
+import (
+	"time"
+
+	"github.com/watermint/dreport/publisher"
+)
 
 type ReportContext struct {
 	// Auth Tokens
@@ -8,8 +12,36 @@ type ReportContext struct {
 	TeamFileToken  string
 	TeamAuditToken string
 
+	// Team members shared across reports
+	Members *MemberDirectory
+
+	// Number of concurrent workers for per-member API calls
+	Concurrency int
+
+	// Period of the report. Zero value means unbounded.
+	Since time.Time
+	Until time.Time
+
 	// Output
 	ReportOutput publisher.Publisher
+
+	// Progress of the report. nil if the output does not support resume.
+	Checkpoint *Checkpoint
+
+	// Name of the report running
+	ReportName string
+
+	// File path of the summary of the report running
+	SummaryFile string
+
+	// Policy and record of errors
+	Errors *ErrorHandler
+}
+
+// HandleError returns the error if the report should be aborted, otherwise records
+// the error of the entity and returns nil.
+func (rc *ReportContext) HandleError(entityId string, err error) error {
+	return rc.Errors.Handle(rc.ReportName, entityId, err)
 }
 
 type ApplicationContext struct {
@@ -0,0 +1,187 @@
+package integration
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/cihub/seelog"
+	"github.com/dropbox/dropbox-sdk-go-unofficial/team"
+)
+
+// MemberDirectory loads team members once, and shares them across reports.
+type MemberDirectory struct {
+	// Load all team members from the API, with raw profiles of the API keyed by team
+	// member id for attributes which are not covered by the SDK.
+	Loader func() ([]*team.TeamMemberInfo, map[string]json.RawMessage, error)
+
+	// Optional cache file. Cache older than CacheTTL is ignored.
+	CacheFile string
+	CacheTTL  time.Duration
+
+	// Identify the team of the cache. Cache of other teams is ignored.
+	TeamId func() (string, error)
+
+	mutex          sync.Mutex
+	loaded         bool
+	members        []*team.TeamMemberInfo
+	profiles       map[string]json.RawMessage
+	byTeamMemberId map[string]*team.TeamMemberInfo
+	byAccountId    map[string]*team.TeamMemberInfo
+	byEmail        map[string]*team.TeamMemberInfo
+}
+
+type memberDirectoryCache struct {
+	Created  time.Time                  `json:"created"`
+	TeamId   string                     `json:"team_id"`
+	Members  []*team.TeamMemberInfo     `json:"members"`
+	Profiles map[string]json.RawMessage `json:"profiles"`
+}
+
+func (d *MemberDirectory) teamId() (string, error) {
+	if d.TeamId == nil {
+		return "", nil
+	}
+	return d.TeamId()
+}
+
+func (d *MemberDirectory) loadCache(teamId string) (*memberDirectoryCache, bool) {
+	content, err := ioutil.ReadFile(d.CacheFile)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			seelog.Warnf("Unable to read member cache '%s': %s", d.CacheFile, err)
+		}
+		return nil, false
+	}
+	cache := &memberDirectoryCache{}
+	if err := json.Unmarshal(content, cache); err != nil {
+		seelog.Warnf("Unable to parse member cache '%s': %s", d.CacheFile, err)
+		return nil, false
+	}
+	if cache.TeamId != teamId {
+		seelog.Infof("Member cache '%s' is for another team. Ignored", d.CacheFile)
+		return nil, false
+	}
+	if time.Now().Sub(cache.Created) > d.CacheTTL {
+		seelog.Info("Member cache expired")
+		return nil, false
+	}
+	if cache.Profiles == nil {
+		seelog.Info("Member cache is created by older version. Ignored")
+		return nil, false
+	}
+	seelog.Infof("Use member cache created at %s", cache.Created.String())
+	return cache, true
+}
+
+func (d *MemberDirectory) saveCache(teamId string, members []*team.TeamMemberInfo, profiles map[string]json.RawMessage) {
+	content, err := json.Marshal(&memberDirectoryCache{
+		Created:  time.Now(),
+		TeamId:   teamId,
+		Members:  members,
+		Profiles: profiles,
+	})
+	if err != nil {
+		seelog.Warnf("Unable to create member cache: %s", err)
+		return
+	}
+	if err := ioutil.WriteFile(d.CacheFile, content, 0600); err != nil {
+		seelog.Warnf("Unable to write member cache '%s': %s", d.CacheFile, err)
+	}
+}
+
+func (d *MemberDirectory) load() error {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	if d.loaded {
+		return nil
+	}
+
+	var members []*team.TeamMemberInfo
+	var profiles map[string]json.RawMessage
+	var teamId string
+	cached := false
+	if d.CacheFile != "" {
+		var err error
+		if teamId, err = d.teamId(); err != nil {
+			seelog.Error("Unable to identify the team of the member cache", err)
+			return err
+		}
+		var cache *memberDirectoryCache
+		if cache, cached = d.loadCache(teamId); cached {
+			members = cache.Members
+			profiles = cache.Profiles
+		}
+	}
+	if !cached {
+		var err error
+		members, profiles, err = d.Loader()
+		if err != nil {
+			return err
+		}
+		if d.CacheFile != "" {
+			d.saveCache(teamId, members, profiles)
+		}
+	}
+
+	d.members = members
+	d.profiles = profiles
+	d.byTeamMemberId = make(map[string]*team.TeamMemberInfo)
+	d.byAccountId = make(map[string]*team.TeamMemberInfo)
+	d.byEmail = make(map[string]*team.TeamMemberInfo)
+	for _, m := range members {
+		d.byTeamMemberId[m.Profile.TeamMemberId] = m
+		if m.Profile.AccountId != "" {
+			d.byAccountId[m.Profile.AccountId] = m
+		}
+		d.byEmail[strings.ToLower(m.Profile.Email)] = m
+	}
+	d.loaded = true
+	return nil
+}
+
+// Members returns all team members. Members are loaded on the first call.
+func (d *MemberDirectory) Members() ([]*team.TeamMemberInfo, error) {
+	if err := d.load(); err != nil {
+		return nil, err
+	}
+	return d.members, nil
+}
+
+// RawProfile returns the profile of the member as returned by the API.
+func (d *MemberDirectory) RawProfile(teamMemberId string) (json.RawMessage, bool) {
+	if err := d.load(); err != nil {
+		return nil, false
+	}
+	p, found := d.profiles[teamMemberId]
+	return p, found
+}
+
+func (d *MemberDirectory) ByTeamMemberId(teamMemberId string) (*team.TeamMemberInfo, bool) {
+	if err := d.load(); err != nil {
+		return nil, false
+	}
+	m, found := d.byTeamMemberId[teamMemberId]
+	return m, found
+}
+
+func (d *MemberDirectory) ByAccountId(accountId string) (*team.TeamMemberInfo, bool) {
+	if err := d.load(); err != nil {
+		return nil, false
+	}
+	m, found := d.byAccountId[accountId]
+	return m, found
+}
+
+// ByEmail finds the member by email address (case insensitive).
+func (d *MemberDirectory) ByEmail(email string) (*team.TeamMemberInfo, bool) {
+	if err := d.load(); err != nil {
+		return nil, false
+	}
+	m, found := d.byEmail[strings.ToLower(email)]
+	return m, found
+}
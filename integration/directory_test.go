@@ -0,0 +1,98 @@
+package integration
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/dropbox/dropbox-sdk-go-unofficial/team"
+	"github.com/dropbox/dropbox-sdk-go-unofficial/users"
+)
+
+func testMembers() []*team.TeamMemberInfo {
+	profile := &team.TeamMemberProfile{}
+	profile.TeamMemberId = "dbmid:1"
+	profile.AccountId = "dbid:1"
+	profile.Email = "Member@example.com"
+	profile.EmailVerified = true
+	profile.Status = &team.TeamMemberStatus{}
+	profile.Status.Tag = "active"
+	profile.Name = &users.Name{DisplayName: "Member"}
+	profile.JoinedOn = time.Date(2016, 10, 1, 9, 0, 0, 0, time.UTC)
+	profile.Groups = []string{"g:1"}
+	role := &team.AdminTier{}
+	role.Tag = "team_admin"
+	return []*team.TeamMemberInfo{{Profile: profile, Role: role}}
+}
+
+func TestMemberDirectoryCache(t *testing.T) {
+	dir, err := ioutil.TempDir("", "dreport")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	loads := 0
+	newDirectory := func(teamId string) *MemberDirectory {
+		return &MemberDirectory{
+			Loader: func() ([]*team.TeamMemberInfo, map[string]json.RawMessage, error) {
+				loads++
+				profiles := map[string]json.RawMessage{
+					"dbmid:1": json.RawMessage(`{"team_member_id":"dbmid:1","member_folder_id":"123"}`),
+				}
+				return testMembers(), profiles, nil
+			},
+			CacheFile: filepath.Join(dir, "members.json"),
+			CacheTTL:  time.Hour,
+			TeamId: func() (string, error) {
+				return teamId, nil
+			},
+		}
+	}
+
+	if _, err := newDirectory("dbtid:a").Members(); err != nil {
+		t.Fatal(err)
+	}
+	if loads != 1 {
+		t.Fatalf("Unexpected loads: %d", loads)
+	}
+
+	// Same team uses the cache
+	cached := newDirectory("dbtid:a")
+	m, found := cached.ByEmail("member@example.com")
+	if !found {
+		t.Fatal("Member should be found")
+	}
+	if loads != 1 {
+		t.Errorf("Cache should be used: %d", loads)
+	}
+	expected := testMembers()[0]
+	if m.Profile.TeamMemberId != expected.Profile.TeamMemberId ||
+		m.Profile.AccountId != expected.Profile.AccountId ||
+		!m.Profile.EmailVerified ||
+		m.Profile.Status.Tag != "active" ||
+		m.Profile.Name.DisplayName != "Member" ||
+		!m.Profile.JoinedOn.Equal(expected.Profile.JoinedOn) ||
+		len(m.Profile.Groups) != 1 ||
+		m.Role.Tag != "team_admin" {
+		t.Errorf("Unexpected member from the cache: %v %v", m.Profile, m.Role)
+	}
+	profile, found := cached.RawProfile("dbmid:1")
+	extra := struct {
+		MemberFolderId string `json:"member_folder_id"`
+	}{}
+	if !found || json.Unmarshal(profile, &extra) != nil || extra.MemberFolderId != "123" {
+		t.Errorf("Unexpected raw profile from the cache: %s", profile)
+	}
+
+	// Other team ignores the cache
+	if _, err := newDirectory("dbtid:b").Members(); err != nil {
+		t.Fatal(err)
+	}
+	if loads != 2 {
+		t.Errorf("Cache of other team should be ignored: %d", loads)
+	}
+}
@@ -0,0 +1,118 @@
+package integration
+
+import (
+	"encoding/csv"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/cihub/seelog"
+)
+
+const (
+	ON_ERROR_ABORT = "abort"
+	ON_ERROR_SKIP  = "skip"
+)
+
+// ErrorHandler handles errors of each entity (member, folder, etc.) of reports.
+// Abort the report, or skip the entity and record the error into the errors file.
+type ErrorHandler struct {
+	Policy     string
+	OutputFile string
+
+	// Append errors to the existing file, to keep errors of the interrupted run.
+	Append bool
+
+	mutex   sync.Mutex
+	outFile *os.File
+	out     *csv.Writer
+	skipped int
+}
+
+func (e *ErrorHandler) createHeader() []string {
+	return []string{
+		"report",
+		"entity-id",
+		"error-summary",
+		"timestamp",
+	}
+}
+
+func (e *ErrorHandler) open() error {
+	if e.out != nil {
+		return nil
+	}
+	flags := os.O_WRONLY | os.O_CREATE | os.O_TRUNC
+	if e.Append {
+		flags = os.O_WRONLY | os.O_CREATE | os.O_APPEND
+	}
+	out, err := os.OpenFile(e.OutputFile, flags, 0644)
+	if err != nil {
+		return err
+	}
+	info, err := out.Stat()
+	if err != nil {
+		out.Close()
+		return err
+	}
+	e.outFile = out
+	e.out = csv.NewWriter(out)
+	if info.Size() > 0 {
+		return nil
+	}
+	return e.out.Write(e.createHeader())
+}
+
+// Handle returns the error if the policy is abort. Otherwise records the error,
+// and returns nil to continue the report.
+func (e *ErrorHandler) Handle(report, entityId string, err error) error {
+	if e == nil || e.Policy != ON_ERROR_SKIP {
+		return err
+	}
+
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
+	seelog.Warnf("Skip '%s' of report '%s': %s", entityId, report, err)
+	e.skipped++
+	if openErr := e.open(); openErr != nil {
+		seelog.Errorf("Unable to create errors file: '%s'", e.OutputFile)
+		return err
+	}
+	if writeErr := e.out.Write([]string{
+		report,
+		entityId,
+		err.Error(),
+		time.Now().Format(time.RFC3339),
+	}); writeErr != nil {
+		return writeErr
+	}
+	e.out.Flush()
+	return e.out.Error()
+}
+
+// Skipped returns number of entities skipped.
+func (e *ErrorHandler) Skipped() int {
+	if e == nil {
+		return 0
+	}
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	return e.skipped
+}
+
+func (e *ErrorHandler) Close() {
+	if e == nil {
+		return
+	}
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	if e.out != nil {
+		e.out.Flush()
+		e.out = nil
+	}
+	if e.outFile != nil {
+		e.outFile.Close()
+		e.outFile = nil
+	}
+}
@@ -0,0 +1,59 @@
+package integration
+
+import (
+	"encoding/csv"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestErrorHandlerAppend(t *testing.T) {
+	dir, err := ioutil.TempDir("", "dreport")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "errors.csv")
+	e := &ErrorHandler{Policy: ON_ERROR_SKIP, OutputFile: path}
+	if err := e.Handle("member-files", "dbmid:1", errors.New("failure")); err != nil {
+		t.Fatal(err)
+	}
+	e.Close()
+
+	e = &ErrorHandler{Policy: ON_ERROR_SKIP, OutputFile: path, Append: true}
+	if err := e.Handle("member-files", "dbmid:2", errors.New("failure")); err != nil {
+		t.Fatal(err)
+	}
+	e.Close()
+
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	rows, err := csv.NewReader(f).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != 3 || rows[0][0] != "report" || rows[1][1] != "dbmid:1" || rows[2][1] != "dbmid:2" {
+		t.Fatalf("Unexpected rows: %v", rows)
+	}
+	if _, err := time.Parse(time.RFC3339, rows[1][3]); err != nil {
+		t.Errorf("Unexpected timestamp: %s", rows[1][3])
+	}
+}
+
+func TestErrorHandlerAbort(t *testing.T) {
+	failure := errors.New("failure")
+	e := &ErrorHandler{Policy: ON_ERROR_ABORT}
+	if err := e.Handle("member-files", "dbmid:1", failure); err != failure {
+		t.Errorf("Error should be returned: %v", err)
+	}
+	if e.Skipped() != 0 {
+		t.Errorf("Unexpected skipped: %d", e.Skipped())
+	}
+}
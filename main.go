@@ -7,14 +7,21 @@ import (
 	"github.com/cihub/seelog"
 	"github.com/dropbox/dropbox-sdk-go-unofficial"
 	"github.com/watermint/dreport/auth"
+	"github.com/watermint/dreport/crawler"
 	"github.com/watermint/dreport/integration"
+	"github.com/watermint/dreport/network"
 	"github.com/watermint/dreport/publisher"
 	"github.com/watermint/dreport/report"
+	"github.com/watermint/dreport/report/audit"
+	"github.com/watermint/dreport/report/group"
 	"github.com/watermint/dreport/report/member"
 	"log"
 	"os"
+	"path/filepath"
 	"strings"
 	"github.com/watermint/dreport/report/sharing"
+	"github.com/watermint/dreport/report/statistics"
+	"time"
 )
 
 var (
@@ -43,88 +50,239 @@ const (
 	`
 )
 
-func Authorise(ac *integration.ApplicationContext, rc *integration.ReportContext, report report.Report) error {
-	permissions := report.RequiredPermissions()
-	seelog.Infof("Report requires following permission(s): %s\n", strings.Join(permissions, ","))
-	seelog.Flush()
+// TokenSources describes where tokens come from. Preset tokens take precedence over
+// the token store, and the interactive dialogue is used only if allowed.
+type TokenSources struct {
+	Preset       auth.PresetTokens
+	Store        *auth.TokenStore
+	Interactive  bool
+	Loopback     bool
+	LoopbackPort int
+}
 
-	for _, p := range permissions {
-		switch p {
-		case auth.PERMISSION_INFO:
-			a := auth.DropboxAuthenticator{
-				Permission: "Team Information",
-				AppName:    ac.AppName,
-				AppKey:     ac.TeamInfoAppKey,
-				AppSecret:  ac.TeamInfoAppSecret,
-			}
-			t, err := a.Authorise()
-			if err != nil {
-				seelog.Errorf("Unable to acquire token for '%s'", a.Permission)
-				return err
-			}
-			rc.TeamInfoToken = t
-
-		case auth.PERMISSION_FILE:
-			a := auth.DropboxAuthenticator{
-				Permission: "Team file access",
-				AppName:    ac.AppName,
-				AppKey:     ac.TeamFileAppKey,
-				AppSecret:  ac.TeamFileAppSecret,
-			}
-			t, err := a.Authorise()
-			if err != nil {
-				seelog.Errorf("Unable to acquire token for '%s'", a.Permission)
-				return err
+func acquireToken(a *auth.DropboxAuthenticator, permission string, sources *TokenSources) (string, error) {
+	if t, ok := sources.Preset[permission]; ok {
+		if err := auth.VerifyToken(t); err != nil {
+			seelog.Errorf("Given token for '%s' is not valid: %s", a.Permission, err)
+			return "", err
+		}
+		seelog.Infof("Use given token for '%s'", a.Permission)
+		return t, nil
+	}
+
+	if sources.Store != nil {
+		if t := sources.Store.Load(permission); t != "" {
+			if err := auth.VerifyToken(t); err == nil {
+				seelog.Infof("Use stored token for '%s'", a.Permission)
+				return t, nil
 			}
-			rc.TeamFileToken = t
-
-		case auth.PERMISSION_AUDIT:
-			a := auth.DropboxAuthenticator{
-				Permission: "Team auditing",
-				AppName:    ac.AppName,
-				AppKey:     ac.TeamAuditAppKey,
-				AppSecret:  ac.TeamAuditAppSecret,
+			seelog.Warnf("Stored token for '%s' is no longer valid", a.Permission)
+		}
+	}
+
+	if !sources.Interactive {
+		seelog.Errorf("Token for '%s' is required. Specify the token by environment variable '%s' or '-token-file'", a.Permission, auth.TokenEnvName(permission))
+		return "", errors.New("Missing token for permission: " + permission)
+	}
+
+	a.Loopback = sources.Loopback
+	a.LoopbackPort = sources.LoopbackPort
+	t, err := a.Authorise()
+	if err != nil {
+		return "", err
+	}
+
+	if sources.Store != nil {
+		if err := sources.Store.Save(permission, t); err != nil {
+			seelog.Warnf("Unable to store token for '%s': %s", a.Permission, err)
+		}
+	}
+	return t, nil
+}
+
+// RequiredPermissions returns union of permissions required by reports.
+func RequiredPermissions(reports []report.Report) []string {
+	permissions := make([]string, 0)
+	required := make(map[string]bool)
+	for _, r := range reports {
+		for _, p := range r.RequiredPermissions() {
+			if !required[p] {
+				required[p] = true
+				permissions = append(permissions, p)
 			}
-			t, err := a.Authorise()
-			if err != nil {
-				seelog.Errorf("Unable to acquire token for '%s'", a.Permission)
-				return err
+		}
+	}
+	return permissions
+}
+
+// OptionalPermissions returns union of permissions optionally used by reports,
+// excluding required permissions.
+func OptionalPermissions(reports []report.Report) []string {
+	permissions := make([]string, 0)
+	added := make(map[string]bool)
+	for _, p := range RequiredPermissions(reports) {
+		added[p] = true
+	}
+	for _, r := range reports {
+		or, ok := r.(report.ReportOptionalPermissions)
+		if !ok {
+			continue
+		}
+		for _, p := range or.OptionalPermissions() {
+			if !added[p] {
+				added[p] = true
+				permissions = append(permissions, p)
 			}
-			rc.TeamAuditToken = t
+		}
+	}
+	return permissions
+}
 
+func authenticator(ac *integration.ApplicationContext, permission string) *auth.DropboxAuthenticator {
+	switch permission {
+	case auth.PERMISSION_INFO:
+		return &auth.DropboxAuthenticator{
+			Permission: "Team Information",
+			AppName:    ac.AppName,
+			AppKey:     ac.TeamInfoAppKey,
+			AppSecret:  ac.TeamInfoAppSecret,
+		}
+	case auth.PERMISSION_FILE:
+		return &auth.DropboxAuthenticator{
+			Permission: "Team file access",
+			AppName:    ac.AppName,
+			AppKey:     ac.TeamFileAppKey,
+			AppSecret:  ac.TeamFileAppSecret,
+		}
+	case auth.PERMISSION_AUDIT:
+		return &auth.DropboxAuthenticator{
+			Permission: "Team auditing",
+			AppName:    ac.AppName,
+			AppKey:     ac.TeamAuditAppKey,
+			AppSecret:  ac.TeamAuditAppSecret,
 		}
+	}
+	return nil
+}
+
+func setToken(rc *integration.ReportContext, permission, token string) {
+	switch permission {
+	case auth.PERMISSION_INFO:
+		rc.TeamInfoToken = token
+	case auth.PERMISSION_FILE:
+		rc.TeamFileToken = token
+	case auth.PERMISSION_AUDIT:
+		rc.TeamAuditToken = token
+	}
+}
+
+func Authorise(ac *integration.ApplicationContext, rc *integration.ReportContext, reports []report.Report, sources *TokenSources) error {
+	permissions := RequiredPermissions(reports)
+	seelog.Infof("Report requires following permission(s): %s\n", strings.Join(permissions, ","))
+	seelog.Flush()
 
+	for _, p := range permissions {
+		a := authenticator(ac, p)
+		if a == nil {
+			continue
+		}
+		t, err := acquireToken(a, p, sources)
+		if err != nil {
+			seelog.Errorf("Unable to acquire token for '%s'", a.Permission)
+			return err
+		}
+		setToken(rc, p, t)
+	}
+
+	// Optional permissions are used only if the token is given or stored.
+	optionalSources := *sources
+	optionalSources.Interactive = false
+	for _, p := range OptionalPermissions(reports) {
+		a := authenticator(ac, p)
+		if a == nil {
+			continue
+		}
+		if _, ok := sources.Preset[p]; !ok && (sources.Store == nil || sources.Store.Load(p) == "") {
+			seelog.Infof("Token for optional permission '%s' is not available", a.Permission)
+			continue
+		}
+		t, err := acquireToken(a, p, &optionalSources)
+		if err != nil {
+			seelog.Warnf("Skip optional permission '%s'", a.Permission)
+			continue
+		}
+		setToken(rc, p, t)
 	}
 
 	return nil
 }
 
-func Revoke(ctx *integration.ReportContext) {
+func revokeToken(token, permission string, sources *TokenSources) {
+	if t, ok := sources.Preset[permission]; ok && t == token {
+		return
+	}
+	client := dropbox.Client(token, dropbox.Options{})
+	client.TokenRevoke()
+	if sources.Store != nil && sources.Store.Load(permission) == token {
+		sources.Store.Delete(permission)
+	}
+}
+
+func Revoke(ctx *integration.ReportContext, sources *TokenSources) {
 	if ctx.TeamInfoToken != "" {
 		seelog.Info("Clean up token: Team Information")
-		client := dropbox.Client(ctx.TeamInfoToken, dropbox.Options{})
-		client.TokenRevoke()
+		revokeToken(ctx.TeamInfoToken, auth.PERMISSION_INFO, sources)
 	}
 	if ctx.TeamFileToken != "" {
 		seelog.Info("Clean up token: Team file access")
-		client := dropbox.Client(ctx.TeamFileToken, dropbox.Options{})
-		client.TokenRevoke()
+		revokeToken(ctx.TeamFileToken, auth.PERMISSION_FILE, sources)
+	}
+	if ctx.TeamAuditToken != "" {
+		seelog.Info("Clean up token: Team auditing")
+		revokeToken(ctx.TeamAuditToken, auth.PERMISSION_AUDIT, sources)
 	}
 }
 
 type Commands struct {
 	SupportedReports []report.Report
 
-	Report           report.Report
+	Reports          []report.Report
 	ReportFile       string
+	ReportFormat     string
 	EnableBom        bool
+	Since            time.Time
+	Until            time.Time
+	TokenSources     *TokenSources
+	KeepToken        bool
+	MemberCache      string
+	MemberCacheTTL   time.Duration
+	Concurrency      int
+	MaxRetryTime     time.Duration
+	Resume           bool
+	OnError          string
 }
 
 var (
-	descReportName = "Report type name"
-	descReportFile = "Output file path"
+	descReportName = "Report type name. Comma separated list of names or 'all' for multiple reports"
+	descReportFile = "Output file path. Output directory path for multiple reports (database file path for sqlite)"
 	descProxy = "HTTP(S) proxy (hostname:port)"
 	descEnableBom = "Add BOM(byte order mark) for output file"
+	descFormat = "Output format (csv, json, jsonl, xlsx, sqlite). Default is determined by extension of the output file"
+	descSince = "Start of the report period (e.g. 2016-10-01 or 2016-10-01T09:00:00+09:00)"
+	descUntil = "End of the report period, exclusive (e.g. 2016-10-31 or 2016-10-31T18:00:00+09:00). A date without time includes the whole day"
+	descTokenStore = "Store tokens and reuse them on later runs (implies -keep-token)"
+	descTokenStorePath = "Token store file path"
+	descTeam = "Team name to identify tokens in the token store"
+	descKeepToken = "Do not revoke tokens at the end of the run"
+	descTokenFile = "Read pre-issued tokens from the file ('-' for stdin). Each line formatted as 'permission=token'"
+	descAuthLoopback = "Authorise through redirect to the local loopback address instead of copy & paste the code"
+	descAuthLoopbackPort = "Port number of the loopback redirect (http://127.0.0.1:port/callback)"
+	descMemberCache = "Cache file path of team members. Cache of other teams is ignored"
+	descMemberCacheTTL = "Time to live of the team member cache (e.g. 30m, 24h)"
+	descConcurrency = "Number of concurrent workers for per-member API calls"
+	descMaxRetryTime = "Maximum total wait time of retries for an API call on rate limit or server errors"
+	descResume = "Resume the report from the checkpoint of the interrupted run, and append rows to the output (csv, jsonl)"
+	descOnError = "Policy on errors of members or folders (abort, skip). Skipped errors are written into the errors file, and exit with code 3"
 )
 
 func (o *Commands) Update() error {
@@ -132,6 +290,28 @@ func (o *Commands) Update() error {
 	reportFile := flag.String("out", "", descReportFile)
 	proxy := flag.String("proxy", "", descProxy)
 	enableBom := flag.Bool("enable-bom", false, descEnableBom)
+	format := flag.String("format", "", descFormat)
+	since := flag.String("since", "", descSince)
+	until := flag.String("until", "", descUntil)
+	tokenStore := flag.Bool("token-store", false, descTokenStore)
+	tokenStorePath := flag.String("token-store-path", auth.DefaultTokenStorePath(), descTokenStorePath)
+	team := flag.String("team", auth.DEFAULT_TEAM, descTeam)
+	keepToken := flag.Bool("keep-token", false, descKeepToken)
+	tokenFile := flag.String("token-file", "", descTokenFile)
+	authLoopback := flag.Bool("auth-loopback", false, descAuthLoopback)
+	authLoopbackPort := flag.Int("auth-loopback-port", auth.DEFAULT_LOOPBACK_PORT, descAuthLoopbackPort)
+	memberCache := flag.String("member-cache", "", descMemberCache)
+	memberCacheTTL := flag.Duration("member-cache-ttl", 24*time.Hour, descMemberCacheTTL)
+	concurrency := flag.Int("concurrency", 1, descConcurrency)
+	maxRetryTime := flag.Duration("max-retry-time", network.DEFAULT_MAX_RETRY_TIME, descMaxRetryTime)
+	resume := flag.Bool("resume", false, descResume)
+	onError := flag.String("on-error", integration.ON_ERROR_ABORT, descOnError)
+
+	for _, r := range o.SupportedReports {
+		if ro, ok := r.(report.ReportOptions); ok {
+			ro.DefineOptions(flag.CommandLine)
+		}
+	}
 
 	flag.Parse()
 
@@ -141,21 +321,72 @@ func (o *Commands) Update() error {
 		return errors.New("Required option: Output file path")
 	}
 
-	r, err := o.FindReport(*reportName)
-	if r == nil || err != nil {
+	r, err := o.FindReports(*reportName)
+	if len(r) == 0 || err != nil {
 		if *reportName != "" {
 			seelog.Errorf("Unsupported Report type: '%s'", *reportName)
 		}
 
 		flag.Usage()
 		o.ShowSupportedReports()
+		if err == nil {
+			err = errors.New("Required option: Report type name")
+		}
 		return err
 	}
 	o.ConfigureProxy(*proxy)
 
-	o.Report = r
+	if o.Since, err = o.ParseTime(*since, false); err != nil {
+		seelog.Errorf("Invalid time format: '%s'", *since)
+		return err
+	}
+	if o.Until, err = o.ParseTime(*until, true); err != nil {
+		seelog.Errorf("Invalid time format: '%s'", *until)
+		return err
+	}
+
+	o.Reports = r
 	o.ReportFile = *reportFile
+	o.ReportFormat = *format
+	if o.ReportFormat == "" {
+		o.ReportFormat = publisher.FormatFromPath(o.ReportFile)
+	}
 	o.EnableBom = *enableBom
+	o.MemberCache = *memberCache
+	o.MemberCacheTTL = *memberCacheTTL
+	o.Concurrency = *concurrency
+	o.MaxRetryTime = *maxRetryTime
+	o.Resume = *resume
+	switch *onError {
+	case integration.ON_ERROR_ABORT, integration.ON_ERROR_SKIP:
+		o.OnError = *onError
+	default:
+		seelog.Errorf("Unsupported error policy: '%s'", *onError)
+		return errors.New("Unsupported error policy")
+	}
+	o.KeepToken = *keepToken
+	o.TokenSources = &TokenSources{
+		Preset:       auth.PresetTokensFromEnv(),
+		Interactive:  *tokenFile != "-" && auth.IsTerminal(os.Stdin),
+		Loopback:     *authLoopback,
+		LoopbackPort: *authLoopbackPort,
+	}
+	if *tokenFile != "" {
+		tokens, err := auth.PresetTokensFromFile(*tokenFile)
+		if err != nil {
+			seelog.Errorf("Unable to load token file '%s': %s", *tokenFile, err)
+			return err
+		}
+		o.TokenSources.Preset = o.TokenSources.Preset.Merge(tokens)
+	}
+	if *tokenStore {
+		o.TokenSources.Store = &auth.TokenStore{
+			Path: *tokenStorePath,
+			Team: *team,
+		}
+		// Stored tokens are reused on later runs, thus never revoked.
+		o.KeepToken = true
+	}
 
 	return nil
 }
@@ -179,6 +410,75 @@ func (o *Commands) FindReport(reportName string) (report.Report, error) {
 	return nil, errors.New("Unsupported Report type")
 }
 
+func (o *Commands) FindReports(reportNames string) ([]report.Report, error) {
+	if reportNames == "all" {
+		return o.SupportedReports, nil
+	}
+	reports := make([]report.Report, 0)
+	for _, n := range strings.Split(reportNames, ",") {
+		n = strings.TrimSpace(n)
+		if n == "" {
+			continue
+		}
+		r, err := o.FindReport(n)
+		if err != nil {
+			return nil, err
+		}
+		reports = append(reports, r)
+	}
+	return reports, nil
+}
+
+func (o *Commands) IsMultipleReports() bool {
+	return len(o.Reports) > 1
+}
+
+// OutputFile returns the output file path of the report. The file is placed under
+// the output directory for multiple reports, except sqlite which stores
+// reports as tables of the database.
+func (o *Commands) OutputFile(r report.Report) string {
+	if !o.IsMultipleReports() || o.ReportFormat == publisher.FORMAT_SQLITE {
+		return o.ReportFile
+	}
+	return filepath.Join(o.ReportFile, r.ReportName()+publisher.FormatExtension(o.ReportFormat))
+}
+
+// ErrorsFile returns the file path to record skipped errors.
+func (o *Commands) ErrorsFile() string {
+	if o.IsMultipleReports() && o.ReportFormat != publisher.FORMAT_SQLITE {
+		return filepath.Join(o.ReportFile, "errors.csv")
+	}
+	return o.ReportFile + ".errors.csv"
+}
+
+// SummaryFile returns the file path to write the summary of the report.
+func (o *Commands) SummaryFile(r report.Report) string {
+	if o.IsMultipleReports() && o.ReportFormat != publisher.FORMAT_SQLITE {
+		return filepath.Join(o.ReportFile, r.ReportName()+".summary.csv")
+	}
+	return o.ReportFile + ".summary.csv"
+}
+
+// ParseTime parses RFC3339 time, or date in local time zone. The date is parsed as
+// the start of the next day if endOfDay is true, to include the whole day into
+// the period which the end is exclusive.
+func (o *Commands) ParseTime(value string, endOfDay bool) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	t, err := time.ParseInLocation("2006-01-02", value, time.Local)
+	if err != nil {
+		return t, err
+	}
+	if endOfDay {
+		t = t.AddDate(0, 0, 1)
+	}
+	return t, nil
+}
+
 func (o *Commands) ConfigureProxy(proxy string) {
 	if proxy != "" {
 		seelog.Info("Explicit proxy configuration: HTTP_PROXY[%s]", proxy)
@@ -188,6 +488,76 @@ func (o *Commands) ConfigureProxy(proxy string) {
 	}
 }
 
+func RunReport(cmd *Commands, rc *integration.ReportContext, r report.Report) error {
+	outputFile := cmd.OutputFile(r)
+	pub, err := publisher.NewPublisher(cmd.ReportFormat, outputFile, r.ReportName(), cmd.EnableBom)
+	if err != nil {
+		seelog.Error("Could not publish report", err)
+		return err
+	}
+
+	// Record progress for publishers which can append rows to the existing output,
+	// and reports which record progress into the checkpoint
+	var cp *integration.Checkpoint
+	resumable, canResume := pub.(publisher.Resumable)
+	if !canResume && cmd.Resume {
+		seelog.Errorf("Resume is not supported for format: %s", cmd.ReportFormat)
+		return errors.New("Unsupported format for resume")
+	}
+	if rr, ok := r.(report.ReportResumable); !ok || !rr.Resumable() {
+		canResume = false
+		if cmd.Resume {
+			seelog.Warnf("Report does not support resume, restart report from the beginning: %s", r.ReportName())
+		}
+	}
+	if canResume {
+		cp = integration.NewCheckpoint(outputFile+".checkpoint", r.ReportName(), resumable)
+	}
+
+	resumed := false
+	if cmd.Resume && canResume {
+		found, err := cp.Load()
+		if err != nil {
+			seelog.Errorf("Unable to load checkpoint: '%s'", cp.Path)
+			return err
+		}
+		if found {
+			seelog.Infof("Resume report from checkpoint: '%s'", cp.Path)
+			if err := resumable.Resume(cp.Offset()); err != nil {
+				seelog.Error("Could not resume report", err)
+				return err
+			}
+			resumed = true
+		}
+	}
+	if !resumed {
+		if err := pub.Open(); err != nil {
+			seelog.Error("Could not publish report", err)
+			return err
+		}
+	}
+	defer pub.Close()
+
+	rc.ReportOutput = pub
+	rc.Checkpoint = cp
+	rc.ReportName = r.ReportName()
+	rc.SummaryFile = cmd.SummaryFile(r)
+
+	seelog.Infof("Start report: %s (%s)", r.ReportName(), outputFile)
+	if err := r.Report(rc); err != nil {
+		if cp != nil {
+			if err := cp.Save(); err != nil {
+				seelog.Warnf("Unable to save checkpoint: %s", err)
+			} else {
+				seelog.Infof("Run with '-resume' to continue from the checkpoint: '%s'", cp.Path)
+			}
+		}
+		return err
+	}
+	cp.Remove()
+	return nil
+}
+
 func ConfigLogger() {
 	logger, err := seelog.LoggerFromConfigAsString(fmt.Sprintf(seeLogXmlTemplate))
 	if err != nil {
@@ -196,7 +566,17 @@ func ConfigLogger() {
 	seelog.ReplaceLogger(logger)
 }
 
+const (
+	EXIT_SUCCESS = 0
+	EXIT_FAILURE = 1
+	EXIT_PARTIAL = 3
+)
+
 func main() {
+	os.Exit(run())
+}
+
+func run() int {
 	ConfigLogger()
 
 	defer seelog.Flush()
@@ -206,25 +586,36 @@ func main() {
 		&member.ReportMemberProfile{},
 		&member.ReportQuotaUsage{},
 		&member.ReportMemberSessions{},
+		&member.ReportMemberFiles{},
+		&member.ReportTeamLinkedApps{},
+		&member.ReportInactiveMembers{},
+		&member.ReportMembershipLifecycle{},
 		&sharing.ReportSharedFolderMembers{},
+		&sharing.ReportTeamFolders{},
+		&sharing.ReportSharedLinks{},
+		&group.ReportGroupMembers{},
+		&audit.ReportTeamAuditEvents{},
+		&statistics.ReportTeamStorageStats{},
+		&statistics.ReportTeamActivityStats{},
+		&statistics.ReportTeamDeviceStats{},
+		&statistics.ReportTeamMembershipStats{},
 	}
 	cmd := Commands{
 		SupportedReports: reports,
 	}
 
 	if err := cmd.Update(); err != nil {
-		return
+		return EXIT_FAILURE
 	}
 
-	pub := &publisher.CsvPublisher{
-		OutputFile: cmd.ReportFile,
-		OmitBom:    cmd.EnableBom,
-	}
-	if err := pub.Open(); err != nil {
-		seelog.Error("Could not publish report", err)
-		return
+	network.InstallRetry(cmd.MaxRetryTime)
+
+	if cmd.IsMultipleReports() && cmd.ReportFormat != publisher.FORMAT_SQLITE {
+		if err := os.MkdirAll(cmd.ReportFile, 0755); err != nil {
+			seelog.Errorf("Unable to create output directory: '%s'", cmd.ReportFile)
+			return EXIT_FAILURE
+		}
 	}
-	defer pub.Close()
 
 	ac := &integration.ApplicationContext{
 		AppName:            "dreport",
@@ -236,17 +627,36 @@ func main() {
 		TeamAuditAppSecret: DropboxBusinessAuditAppSecret,
 	}
 	rc := &integration.ReportContext{
-		ReportOutput: pub,
+		Concurrency: cmd.Concurrency,
+		Since:       cmd.Since,
+		Until:       cmd.Until,
+		Errors: &integration.ErrorHandler{
+			Policy:     cmd.OnError,
+			OutputFile: cmd.ErrorsFile(),
+			Append:     cmd.Resume,
+		},
 	}
+	rc.Members = crawler.NewMemberDirectory(rc, cmd.MemberCache, cmd.MemberCacheTTL)
+	defer rc.Errors.Close()
 
-	if err := Authorise(ac, rc, cmd.Report); err != nil {
+	if err := Authorise(ac, rc, cmd.Reports, cmd.TokenSources); err != nil {
 		seelog.Error("Unable to acquire enough authorisations.")
-		return
+		return EXIT_FAILURE
+	}
+	if !cmd.KeepToken {
+		defer Revoke(rc, cmd.TokenSources)
 	}
-	defer Revoke(rc)
 
-	seelog.Info("Start report: ", cmd.Report.ReportName())
-	if err := cmd.Report.Report(rc); err != nil {
-		seelog.Error(err)
+	exitCode := EXIT_SUCCESS
+	for _, r := range cmd.Reports {
+		if err := RunReport(&cmd, rc, r); err != nil {
+			seelog.Error(err)
+			exitCode = EXIT_FAILURE
+		}
+	}
+	if exitCode == EXIT_SUCCESS && rc.Errors.Skipped() > 0 {
+		seelog.Warnf("%d error(s) skipped. See errors file: '%s'", rc.Errors.Skipped(), cmd.ErrorsFile())
+		exitCode = EXIT_PARTIAL
 	}
+	return exitCode
 }
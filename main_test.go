@@ -0,0 +1,42 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseTime(t *testing.T) {
+	o := &Commands{}
+
+	if v, err := o.ParseTime("", true); err != nil || !v.IsZero() {
+		t.Errorf("Empty value should be zero time: %v %v", v, err)
+	}
+
+	v, err := o.ParseTime("2017-03-31T18:00:00+09:00", true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !v.Equal(time.Date(2017, 3, 31, 9, 0, 0, 0, time.UTC)) {
+		t.Errorf("Unexpected time: %v", v)
+	}
+
+	since, err := o.ParseTime("2017-03-31", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !since.Equal(time.Date(2017, 3, 31, 0, 0, 0, 0, time.Local)) {
+		t.Errorf("Date should be the start of the day: %v", since)
+	}
+
+	until, err := o.ParseTime("2017-03-31", true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !until.Equal(time.Date(2017, 4, 1, 0, 0, 0, 0, time.Local)) {
+		t.Errorf("Date should be the end of the day: %v", until)
+	}
+
+	if _, err := o.ParseTime("2017/03/31", false); err == nil {
+		t.Error("Invalid format should be an error")
+	}
+}
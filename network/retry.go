@@ -0,0 +1,163 @@
+package network
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cihub/seelog"
+)
+
+const (
+	DEFAULT_MAX_RETRY_TIME = 10 * time.Minute
+
+	retryBaseDelay = 1 * time.Second
+	retryMaxDelay  = 1 * time.Minute
+)
+
+var (
+	// Endpoints which are not idempotent. e.g. the authorisation code can be
+	// exchanged only once, thus the retry always fails with `invalid_grant`.
+	retryExcludedPaths = []string{
+		"/oauth2/token",
+		"/2/auth/token/revoke",
+	}
+
+	// Replaced by tests
+	retrySleep = time.Sleep
+)
+
+// RetryTransport retries requests on rate limit (429) and transient server errors (5xx),
+// or transport errors, except requests to non idempotent OAuth2 token and revoke
+// endpoints. Waits for `Retry-After` if the server specified, otherwise
+// waits with exponential backoff with jitter. Gives up when the total wait time
+// exceeds MaxRetryTime, and returns the last response.
+type RetryTransport struct {
+	Base         http.RoundTripper
+	MaxRetryTime time.Duration
+}
+
+// InstallRetry wraps http.DefaultTransport with RetryTransport. The SDK, OAuth2
+// and the RPC client use the default transport, thus all API calls are retried.
+func InstallRetry(maxRetryTime time.Duration) {
+	if _, ok := http.DefaultTransport.(*RetryTransport); ok {
+		return
+	}
+	rand.Seed(time.Now().UnixNano())
+	http.DefaultTransport = &RetryTransport{
+		Base:         http.DefaultTransport,
+		MaxRetryTime: maxRetryTime,
+	}
+}
+
+func isExcluded(req *http.Request) bool {
+	for _, p := range retryExcludedPaths {
+		if strings.HasSuffix(req.URL.Path, p) {
+			return true
+		}
+	}
+	return false
+}
+
+func isRetryable(statusCode int) bool {
+	switch statusCode {
+	case http.StatusTooManyRequests,
+		http.StatusInternalServerError,
+		http.StatusBadGateway,
+		http.StatusServiceUnavailable,
+		http.StatusGatewayTimeout:
+		return true
+	default:
+		return false
+	}
+}
+
+// retryAfter returns wait duration specified by `Retry-After` header.
+// Returns false if the header is not specified or invalid.
+func retryAfter(resp *http.Response) (time.Duration, bool) {
+	value := resp.Header.Get("Retry-After")
+	if value == "" {
+		return 0, false
+	}
+	if sec, err := strconv.Atoi(value); err == nil && sec >= 0 {
+		return time.Duration(sec) * time.Second, true
+	}
+	if t, err := http.ParseTime(value); err == nil {
+		wait := t.Sub(time.Now())
+		if wait < 0 {
+			wait = 0
+		}
+		return wait, true
+	}
+	return 0, false
+}
+
+func backoff(attempt int) time.Duration {
+	delay := retryBaseDelay << uint(attempt)
+	if delay > retryMaxDelay || delay <= 0 {
+		delay = retryMaxDelay
+	}
+	// Jitter in [delay/2, delay)
+	return delay/2 + time.Duration(rand.Int63n(int64(delay/2)))
+}
+
+func (r *RetryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if isExcluded(req) {
+		return r.Base.RoundTrip(req)
+	}
+
+	var body []byte
+	if req.Body != nil {
+		var err error
+		body, err = ioutil.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var waited time.Duration
+	for attempt := 0; ; attempt++ {
+		attemptReq := *req
+		if body != nil {
+			attemptReq.Body = ioutil.NopCloser(bytes.NewReader(body))
+		}
+
+		resp, err := r.Base.RoundTrip(&attemptReq)
+
+		var wait time.Duration
+		var reason string
+		switch {
+		case err != nil:
+			wait = backoff(attempt)
+			reason = err.Error()
+		case isRetryable(resp.StatusCode):
+			if ra, ok := retryAfter(resp); ok {
+				wait = ra
+			} else {
+				wait = backoff(attempt)
+			}
+			reason = resp.Status
+		default:
+			return resp, nil
+		}
+
+		if waited+wait > r.MaxRetryTime {
+			seelog.Errorf("Give up retry '%s' after %d attempt(s): %s", req.URL.Path, attempt+1, reason)
+			return resp, err
+		}
+		if resp != nil {
+			io.Copy(ioutil.Discard, resp.Body)
+			resp.Body.Close()
+		}
+
+		seelog.Warnf("Retry '%s' in %s (attempt %d): %s", req.URL.Path, wait, attempt+1, reason)
+		retrySleep(wait)
+		waited += wait
+	}
+}
@@ -0,0 +1,139 @@
+package network
+
+import (
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+type stubTransport struct {
+	statusCodes []int
+	header      http.Header
+	requests    int
+}
+
+func (s *stubTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	s.requests++
+	if len(s.statusCodes) == 0 {
+		return nil, errors.New("connection reset")
+	}
+	code := s.statusCodes[0]
+	if len(s.statusCodes) > 1 {
+		s.statusCodes = s.statusCodes[1:]
+	}
+	return &http.Response{
+		StatusCode: code,
+		Status:     http.StatusText(code),
+		Header:     s.header,
+		Body:       ioutil.NopCloser(strings.NewReader("")),
+	}, nil
+}
+
+func stubSleep() *[]time.Duration {
+	waits := make([]time.Duration, 0)
+	retrySleep = func(d time.Duration) {
+		waits = append(waits, d)
+	}
+	return &waits
+}
+
+func TestRetryAfter(t *testing.T) {
+	header := func(v string) *http.Response {
+		resp := &http.Response{Header: http.Header{}}
+		if v != "" {
+			resp.Header.Set("Retry-After", v)
+		}
+		return resp
+	}
+
+	if d, ok := retryAfter(header("15")); !ok || d != 15*time.Second {
+		t.Errorf("Unexpected wait: %s %t", d, ok)
+	}
+	future := time.Now().Add(30 * time.Second).UTC().Format(http.TimeFormat)
+	if d, ok := retryAfter(header(future)); !ok || d <= 20*time.Second || d > 30*time.Second {
+		t.Errorf("Unexpected wait: %s %t", d, ok)
+	}
+	past := time.Now().Add(-time.Hour).UTC().Format(http.TimeFormat)
+	if d, ok := retryAfter(header(past)); !ok || d != 0 {
+		t.Errorf("Unexpected wait: %s %t", d, ok)
+	}
+	for _, v := range []string{"", "-1", "soon"} {
+		if _, ok := retryAfter(header(v)); ok {
+			t.Errorf("Should be invalid: '%s'", v)
+		}
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	for attempt := 0; attempt < 100; attempt++ {
+		d := backoff(attempt)
+		limit := retryBaseDelay << uint(attempt)
+		if limit > retryMaxDelay || limit <= 0 {
+			limit = retryMaxDelay
+		}
+		if d < limit/2 || d >= limit {
+			t.Errorf("Unexpected backoff for attempt %d: %s", attempt, d)
+		}
+	}
+}
+
+func TestRetryTransport(t *testing.T) {
+	defer func() { retrySleep = time.Sleep }()
+	waits := stubSleep()
+
+	base := &stubTransport{
+		statusCodes: []int{http.StatusTooManyRequests, http.StatusServiceUnavailable, http.StatusOK},
+		header:      http.Header{"Retry-After": []string{"2"}},
+	}
+	r := &RetryTransport{Base: base, MaxRetryTime: time.Minute}
+	req, _ := http.NewRequest("POST", "https://api.dropboxapi.com/2/team/members/list", strings.NewReader("{}"))
+	resp, err := r.RoundTrip(req)
+	if err != nil || resp.StatusCode != http.StatusOK {
+		t.Fatalf("Unexpected result: %v %v", resp, err)
+	}
+	if base.requests != 3 || len(*waits) != 2 || (*waits)[0] != 2*time.Second {
+		t.Errorf("Unexpected retries: %d %v", base.requests, *waits)
+	}
+}
+
+func TestRetryTransportGiveUp(t *testing.T) {
+	defer func() { retrySleep = time.Sleep }()
+	waits := stubSleep()
+
+	base := &stubTransport{
+		statusCodes: []int{http.StatusTooManyRequests},
+		header:      http.Header{"Retry-After": []string{"10"}},
+	}
+	r := &RetryTransport{Base: base, MaxRetryTime: 35 * time.Second}
+	req, _ := http.NewRequest("POST", "https://api.dropboxapi.com/2/team/members/list", strings.NewReader("{}"))
+	resp, err := r.RoundTrip(req)
+	if err != nil || resp.StatusCode != http.StatusTooManyRequests {
+		t.Fatalf("Should return the last response: %v %v", resp, err)
+	}
+	if base.requests != 4 || len(*waits) != 3 {
+		t.Errorf("Unexpected retries: %d %v", base.requests, *waits)
+	}
+}
+
+func TestRetryTransportExcluded(t *testing.T) {
+	defer func() { retrySleep = time.Sleep }()
+	waits := stubSleep()
+
+	for _, url := range []string{
+		"https://api.dropboxapi.com/oauth2/token",
+		"https://api.dropboxapi.com/2/auth/token/revoke",
+	} {
+		base := &stubTransport{}
+		r := &RetryTransport{Base: base, MaxRetryTime: time.Minute}
+		req, _ := http.NewRequest("POST", url, strings.NewReader("code=abc"))
+		if _, err := r.RoundTrip(req); err == nil {
+			t.Errorf("Error should be returned: %s", url)
+		}
+		if base.requests != 1 || len(*waits) != 0 {
+			t.Errorf("Should not retry: %s", url)
+		}
+	}
+}
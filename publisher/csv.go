@@ -2,11 +2,16 @@ package publisher
 
 import (
 	"encoding/csv"
+	"io"
 	"os"
 
 	"github.com/cihub/seelog"
 )
 
+var (
+	bomUtf8 = []byte{0xef, 0xbb, 0xbf}
+)
+
 type CsvPublisher struct {
 	OutputFile string
 	OmitBom    bool
@@ -14,9 +19,13 @@ type CsvPublisher struct {
 	outFile    *os.File
 	outCsv     *csv.Writer
 	debugCsv   *csv.Writer
+	resumed    bool
 }
 
 func (c *CsvPublisher) Headers(headers []string) error {
+	if c.resumed {
+		return nil
+	}
 	c.debugCsv.Write(headers)
 	return c.outCsv.Write(headers)
 }
@@ -32,15 +41,40 @@ func (c *CsvPublisher) Open() error {
 		seelog.Errorf("Unable to create file: '%s'", c.OutputFile)
 		return err
 	}
+	c.open(out, 0)
+	return nil
+}
+
+func (c *CsvPublisher) open(out *os.File, offset int64) {
 	c.outFile = out
 	c.outCsv = csv.NewWriter(out)
 	c.debugCsv = csv.NewWriter(os.Stdout)
 
-	bomUtf8 := []byte{0xef, 0xbb, 0xbf}
-	if c.OmitBom {
+	if c.OmitBom && offset == 0 {
 		c.outFile.Write(bomUtf8)
 	}
+}
+
+func (c *CsvPublisher) Offset() (int64, error) {
+	c.outCsv.Flush()
+	if err := c.outCsv.Error(); err != nil {
+		return 0, err
+	}
+	return c.outFile.Seek(0, io.SeekCurrent)
+}
 
+func (c *CsvPublisher) Resume(offset int64) error {
+	out, err := openForResume(c.OutputFile, offset)
+	if err != nil {
+		return err
+	}
+	// Headers are written if the output has more than BOM.
+	headerOffset := int64(0)
+	if c.OmitBom {
+		headerOffset = int64(len(bomUtf8))
+	}
+	c.resumed = offset > headerOffset
+	c.open(out, offset)
 	return nil
 }
 
@@ -0,0 +1,88 @@
+package publisher
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestCsvPublisherResume(t *testing.T) {
+	dir, err := ioutil.TempDir("", "dreport")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "out.csv")
+	p := &CsvPublisher{OutputFile: path}
+	if err := p.Open(); err != nil {
+		t.Fatal(err)
+	}
+	p.Headers([]string{"name"})
+	p.Row([]string{"a"})
+	offset, err := p.Offset()
+	if err != nil {
+		t.Fatal(err)
+	}
+	p.Row([]string{"discarded"})
+	p.Close()
+
+	p = &CsvPublisher{OutputFile: path}
+	if err := p.Resume(offset); err != nil {
+		t.Fatal(err)
+	}
+	p.Headers([]string{"name"})
+	p.Row([]string{"b"})
+	p.Close()
+
+	content, _ := ioutil.ReadFile(path)
+	if string(content) != "name\na\nb\n" {
+		t.Errorf("Unexpected output: %q", content)
+	}
+}
+
+func TestCsvPublisherResumeBom(t *testing.T) {
+	dir, err := ioutil.TempDir("", "dreport")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	// Checkpoint saved before headers records the offset of BOM only
+	path := filepath.Join(dir, "out.csv")
+	p := &CsvPublisher{OutputFile: path, OmitBom: true}
+	if err := p.Open(); err != nil {
+		t.Fatal(err)
+	}
+	offset, err := p.Offset()
+	if err != nil {
+		t.Fatal(err)
+	}
+	p.Close()
+
+	p = &CsvPublisher{OutputFile: path, OmitBom: true}
+	if err := p.Resume(offset); err != nil {
+		t.Fatal(err)
+	}
+	p.Headers([]string{"name"})
+	p.Row([]string{"a"})
+	offset, err = p.Offset()
+	if err != nil {
+		t.Fatal(err)
+	}
+	p.Close()
+
+	p = &CsvPublisher{OutputFile: path, OmitBom: true}
+	if err := p.Resume(offset); err != nil {
+		t.Fatal(err)
+	}
+	p.Headers([]string{"name"})
+	p.Row([]string{"b"})
+	p.Close()
+
+	content, _ := ioutil.ReadFile(path)
+	if string(content) != "\xef\xbb\xbfname\na\nb\n" {
+		t.Errorf("Unexpected output: %q", content)
+	}
+}
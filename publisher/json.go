@@ -0,0 +1,92 @@
+package publisher
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/cihub/seelog"
+)
+
+// JsonPublisher writes rows as an array of objects keyed by header names.
+type JsonPublisher struct {
+	OutputFile string
+
+	headers []string
+	numRows int
+	outFile *os.File
+	out     *bufio.Writer
+}
+
+// encodeObject encodes the row as an JSON object. Keys are ordered as headers.
+func encodeObject(headers []string, data []string) ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteString("{")
+	for i, v := range data {
+		if i > 0 {
+			buf.WriteString(",")
+		}
+		key := fmt.Sprintf("column%d", i+1)
+		if i < len(headers) {
+			key = headers[i]
+		}
+		k, err := json.Marshal(key)
+		if err != nil {
+			return nil, err
+		}
+		d, err := json.Marshal(v)
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(k)
+		buf.WriteString(":")
+		buf.Write(d)
+	}
+	buf.WriteString("}")
+	return buf.Bytes(), nil
+}
+
+func (j *JsonPublisher) Headers(headers []string) error {
+	j.headers = headers
+	return nil
+}
+
+func (j *JsonPublisher) Row(data []string) error {
+	obj, err := encodeObject(j.headers, data)
+	if err != nil {
+		return err
+	}
+	if j.numRows > 0 {
+		j.out.WriteString(",\n")
+	}
+	j.numRows++
+	_, err = j.out.Write(obj)
+	return err
+}
+
+func (j *JsonPublisher) Open() error {
+	out, err := os.Create(j.OutputFile)
+	if err != nil {
+		seelog.Errorf("Unable to create file: '%s'", j.OutputFile)
+		return err
+	}
+	j.outFile = out
+	j.out = bufio.NewWriter(out)
+	j.numRows = 0
+	_, err = j.out.WriteString("[\n")
+	return err
+}
+
+func (j *JsonPublisher) Close() {
+	if j.out != nil {
+		j.out.WriteString("\n]\n")
+		j.out.Flush()
+		j.out = nil
+	}
+	if j.outFile != nil {
+		j.outFile.Close()
+		j.outFile = nil
+	}
+}
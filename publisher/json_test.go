@@ -0,0 +1,144 @@
+package publisher
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestEncodeObject(t *testing.T) {
+	obj, err := encodeObject([]string{"b", "a"}, []string{"1", "\"quoted\"", "extra"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := `{"b":"1","a":"\"quoted\"","column3":"extra"}`
+	if string(obj) != expected {
+		t.Errorf("Unexpected object: %s", obj)
+	}
+}
+
+func TestJsonPublisher(t *testing.T) {
+	dir, err := ioutil.TempDir("", "dreport")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "out.json")
+	p := &JsonPublisher{OutputFile: path}
+	if err := p.Open(); err != nil {
+		t.Fatal(err)
+	}
+	p.Headers([]string{"name", "size"})
+	p.Row([]string{"a", "1"})
+	p.Row([]string{"b", "2"})
+	p.Close()
+
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rows := make([]map[string]string, 0)
+	if err := json.Unmarshal(content, &rows); err != nil {
+		t.Fatalf("Invalid JSON: %s", err)
+	}
+	if len(rows) != 2 || rows[0]["name"] != "a" || rows[1]["size"] != "2" {
+		t.Errorf("Unexpected rows: %v", rows)
+	}
+}
+
+func TestJsonPublisherEmpty(t *testing.T) {
+	dir, err := ioutil.TempDir("", "dreport")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "out.json")
+	p := &JsonPublisher{OutputFile: path}
+	if err := p.Open(); err != nil {
+		t.Fatal(err)
+	}
+	p.Headers([]string{"name"})
+	p.Close()
+
+	content, _ := ioutil.ReadFile(path)
+	rows := make([]map[string]string, 0)
+	if err := json.Unmarshal(content, &rows); err != nil || len(rows) != 0 {
+		t.Errorf("Unexpected output: %s %v", content, err)
+	}
+}
+
+func TestJsonLinesPublisher(t *testing.T) {
+	dir, err := ioutil.TempDir("", "dreport")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "out.jsonl")
+	p := &JsonLinesPublisher{OutputFile: path}
+	if err := p.Open(); err != nil {
+		t.Fatal(err)
+	}
+	p.Headers([]string{"name"})
+	p.Row([]string{"a"})
+	p.Row([]string{"b"})
+	p.Close()
+
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(content)), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("Unexpected lines: %v", lines)
+	}
+	for i, expected := range []string{"a", "b"} {
+		row := make(map[string]string)
+		if err := json.Unmarshal([]byte(lines[i]), &row); err != nil {
+			t.Fatal(err)
+		}
+		if row["name"] != expected {
+			t.Errorf("Unexpected row: %v", row)
+		}
+	}
+}
+
+func TestJsonLinesPublisherResume(t *testing.T) {
+	dir, err := ioutil.TempDir("", "dreport")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "out.jsonl")
+	p := &JsonLinesPublisher{OutputFile: path}
+	if err := p.Open(); err != nil {
+		t.Fatal(err)
+	}
+	p.Headers([]string{"name"})
+	p.Row([]string{"a"})
+	offset, err := p.Offset()
+	if err != nil {
+		t.Fatal(err)
+	}
+	p.Row([]string{"discarded"})
+	p.Close()
+
+	p = &JsonLinesPublisher{OutputFile: path}
+	if err := p.Resume(offset); err != nil {
+		t.Fatal(err)
+	}
+	p.Headers([]string{"name"})
+	p.Row([]string{"b"})
+	p.Close()
+
+	content, _ := ioutil.ReadFile(path)
+	if string(content) != "{\"name\":\"a\"}\n{\"name\":\"b\"}\n" {
+		t.Errorf("Unexpected output: %q", content)
+	}
+}
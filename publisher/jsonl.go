@@ -0,0 +1,74 @@
+package publisher
+
+import (
+	"bufio"
+	"io"
+	"os"
+
+	"github.com/cihub/seelog"
+)
+
+// JsonLinesPublisher writes an object keyed by header names per line.
+type JsonLinesPublisher struct {
+	OutputFile string
+
+	headers []string
+	outFile *os.File
+	out     *bufio.Writer
+}
+
+func (j *JsonLinesPublisher) Offset() (int64, error) {
+	if err := j.out.Flush(); err != nil {
+		return 0, err
+	}
+	return j.outFile.Seek(0, io.SeekCurrent)
+}
+
+func (j *JsonLinesPublisher) Resume(offset int64) error {
+	out, err := openForResume(j.OutputFile, offset)
+	if err != nil {
+		return err
+	}
+	j.outFile = out
+	j.out = bufio.NewWriter(out)
+	return nil
+}
+
+func (j *JsonLinesPublisher) Headers(headers []string) error {
+	j.headers = headers
+	return nil
+}
+
+func (j *JsonLinesPublisher) Row(data []string) error {
+	obj, err := encodeObject(j.headers, data)
+	if err != nil {
+		return err
+	}
+	if _, err := j.out.Write(obj); err != nil {
+		return err
+	}
+	_, err = j.out.WriteString("\n")
+	return err
+}
+
+func (j *JsonLinesPublisher) Open() error {
+	out, err := os.Create(j.OutputFile)
+	if err != nil {
+		seelog.Errorf("Unable to create file: '%s'", j.OutputFile)
+		return err
+	}
+	j.outFile = out
+	j.out = bufio.NewWriter(out)
+	return nil
+}
+
+func (j *JsonLinesPublisher) Close() {
+	if j.out != nil {
+		j.out.Flush()
+		j.out = nil
+	}
+	if j.outFile != nil {
+		j.outFile.Close()
+		j.outFile = nil
+	}
+}
@@ -1,8 +1,109 @@
 package publisher
 
+import (
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/cihub/seelog"
+)
+
+const (
+	FORMAT_CSV    = "csv"
+	FORMAT_JSON   = "json"
+	FORMAT_JSONL  = "jsonl"
+	FORMAT_XLSX   = "xlsx"
+	FORMAT_SQLITE = "sqlite"
+)
+
 type Publisher interface {
 	Headers(headers []string) error
 	Row(data []string) error
 	Open() error
 	Close()
 }
+
+// Resumable is implemented by publishers which can append rows to the output of
+// an interrupted run.
+type Resumable interface {
+	// Offset flushes buffered rows, and returns the size of the output.
+	Offset() (int64, error)
+
+	// Resume opens the output instead of Open. The output is truncated at the offset to
+	// discard rows written after the offset recorded, then rows are appended.
+	// Headers are not written again if the output already has headers at the offset.
+	Resume(offset int64) error
+}
+
+func openForResume(path string, offset int64) (*os.File, error) {
+	out, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		seelog.Errorf("Unable to open file: '%s'", path)
+		return nil, err
+	}
+	if err := out.Truncate(offset); err != nil {
+		out.Close()
+		return nil, err
+	}
+	if _, err := out.Seek(offset, io.SeekStart); err != nil {
+		out.Close()
+		return nil, err
+	}
+	return out, nil
+}
+
+// FormatFromPath returns output format determined by the extension of the path.
+// Returns FORMAT_CSV for unknown extensions.
+func FormatFromPath(path string) string {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		return FORMAT_JSON
+	case ".jsonl":
+		return FORMAT_JSONL
+	case ".xlsx":
+		return FORMAT_XLSX
+	case ".sqlite", ".sqlite3", ".db":
+		return FORMAT_SQLITE
+	default:
+		return FORMAT_CSV
+	}
+}
+
+// FormatExtension returns the file extension for the format.
+func FormatExtension(format string) string {
+	return "." + format
+}
+
+// NewPublisher creates the publisher for the format. The name is used as the sheet
+// or the table name for formats which support it.
+func NewPublisher(format, outputFile, name string, enableBom bool) (Publisher, error) {
+	switch format {
+	case FORMAT_CSV:
+		return &CsvPublisher{
+			OutputFile: outputFile,
+			OmitBom:    enableBom,
+		}, nil
+	case FORMAT_JSON:
+		return &JsonPublisher{
+			OutputFile: outputFile,
+		}, nil
+	case FORMAT_JSONL:
+		return &JsonLinesPublisher{
+			OutputFile: outputFile,
+		}, nil
+	case FORMAT_XLSX:
+		return &XlsxPublisher{
+			OutputFile: outputFile,
+			SheetName:  name,
+		}, nil
+	case FORMAT_SQLITE:
+		return &SqlitePublisher{
+			OutputFile: outputFile,
+			TableName:  name,
+		}, nil
+	default:
+		return nil, errors.New("Unsupported format: " + format)
+	}
+}
@@ -0,0 +1,161 @@
+package publisher
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/cihub/seelog"
+)
+
+const (
+	sqliteRunTimestampColumn = "run_timestamp"
+)
+
+var (
+	sqliteIdentifierPattern = regexp.MustCompile(`[^A-Za-z0-9_]+`)
+)
+
+// SqlitePublisher writes rows into the table of SQLite database. Rows are appended
+// to the table if the table already exists, with the timestamp of the run.
+// The driver requires cgo, thus the publisher is not available on builds without cgo.
+type SqlitePublisher struct {
+	OutputFile string
+	TableName  string
+
+	db           *sql.DB
+	tx           *sql.Tx
+	insert       *sql.Stmt
+	numColumns   int
+	runTimestamp string
+}
+
+func sqliteIdentifier(name string) string {
+	return strings.Trim(sqliteIdentifierPattern.ReplaceAllString(name, "_"), "_")
+}
+
+func sqliteQuote(identifier string) string {
+	return `"` + strings.Replace(identifier, `"`, `""`, -1) + `"`
+}
+
+func (s *SqlitePublisher) existingColumns(table string) (map[string]bool, error) {
+	rows, err := s.tx.Query(fmt.Sprintf("PRAGMA table_info(%s)", sqliteQuote(table)))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	columns := make(map[string]bool)
+	for rows.Next() {
+		var cid int
+		var name, colType string
+		var notNull, pk int
+		var defaultValue sql.NullString
+		if err := rows.Scan(&cid, &name, &colType, &notNull, &defaultValue, &pk); err != nil {
+			return nil, err
+		}
+		columns[name] = true
+	}
+	return columns, rows.Err()
+}
+
+func (s *SqlitePublisher) Headers(headers []string) error {
+	table := sqliteQuote(sqliteIdentifier(s.TableName))
+	columns := []string{sqliteRunTimestampColumn}
+	for _, h := range headers {
+		columns = append(columns, sqliteIdentifier(h))
+	}
+
+	defs := make([]string, 0, len(columns))
+	for _, c := range columns {
+		defs = append(defs, sqliteQuote(c)+" TEXT")
+	}
+	if _, err := s.tx.Exec(fmt.Sprintf("CREATE TABLE IF NOT EXISTS %s (%s)", table, strings.Join(defs, ", "))); err != nil {
+		seelog.Errorf("Unable to create table: '%s'", s.TableName)
+		return err
+	}
+
+	// Add columns which does not exist in the table created by previous runs.
+	existing, err := s.existingColumns(sqliteIdentifier(s.TableName))
+	if err != nil {
+		return err
+	}
+	for _, c := range columns {
+		if existing[c] {
+			continue
+		}
+		if _, err := s.tx.Exec(fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s TEXT", table, sqliteQuote(c))); err != nil {
+			seelog.Errorf("Unable to add column '%s' to table '%s'", c, s.TableName)
+			return err
+		}
+	}
+
+	quoted := make([]string, 0, len(columns))
+	placeholders := make([]string, 0, len(columns))
+	for _, c := range columns {
+		quoted = append(quoted, sqliteQuote(c))
+		placeholders = append(placeholders, "?")
+	}
+	insert, err := s.tx.Prepare(fmt.Sprintf("INSERT INTO %s (%s) VALUES (%s)", table, strings.Join(quoted, ", "), strings.Join(placeholders, ", ")))
+	if err != nil {
+		return err
+	}
+	s.insert = insert
+	s.numColumns = len(headers)
+	return nil
+}
+
+func (s *SqlitePublisher) Row(data []string) error {
+	if s.insert == nil {
+		return errors.New("Headers must be written before rows")
+	}
+	values := make([]interface{}, s.numColumns+1)
+	values[0] = s.runTimestamp
+	for i := 0; i < s.numColumns && i < len(data); i++ {
+		values[i+1] = data[i]
+	}
+	_, err := s.insert.Exec(values...)
+	return err
+}
+
+func (s *SqlitePublisher) Open() error {
+	if !sqliteAvailable {
+		seelog.Error("SQLite output is not supported by this build. The build requires cgo")
+		return errors.New("Unsupported format on this build: " + FORMAT_SQLITE)
+	}
+	db, err := sql.Open("sqlite3", s.OutputFile)
+	if err != nil {
+		seelog.Errorf("Unable to open database: '%s'", s.OutputFile)
+		return err
+	}
+	tx, err := db.Begin()
+	if err != nil {
+		db.Close()
+		seelog.Errorf("Unable to open database: '%s'", s.OutputFile)
+		return err
+	}
+	s.db = db
+	s.tx = tx
+	s.runTimestamp = time.Now().UTC().Format(time.RFC3339)
+	return nil
+}
+
+func (s *SqlitePublisher) Close() {
+	if s.insert != nil {
+		s.insert.Close()
+		s.insert = nil
+	}
+	if s.tx != nil {
+		if err := s.tx.Commit(); err != nil {
+			seelog.Errorf("Unable to commit rows into database: '%s'", s.OutputFile)
+		}
+		s.tx = nil
+	}
+	if s.db != nil {
+		s.db.Close()
+		s.db = nil
+	}
+}
@@ -0,0 +1,11 @@
+// +build cgo
+
+package publisher
+
+import (
+	_ "github.com/mattn/go-sqlite3"
+)
+
+const (
+	sqliteAvailable = true
+)
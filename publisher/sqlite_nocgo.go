@@ -0,0 +1,7 @@
+// +build !cgo
+
+package publisher
+
+const (
+	sqliteAvailable = false
+)
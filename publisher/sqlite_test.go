@@ -0,0 +1,48 @@
+// +build cgo
+
+package publisher
+
+import (
+	"database/sql"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestSqlitePublisher(t *testing.T) {
+	dir, err := ioutil.TempDir("", "dreport")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "out.db")
+	p := &SqlitePublisher{OutputFile: path, TableName: "member-list"}
+	if err := p.Open(); err != nil {
+		t.Fatal(err)
+	}
+	if err := p.Row([]string{"a"}); err == nil {
+		t.Error("Row before headers should be an error")
+	}
+	if err := p.Headers([]string{"email", "status"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := p.Row([]string{"a@example.com", "active"}); err != nil {
+		t.Fatal(err)
+	}
+	p.Close()
+
+	db, err := sql.Open("sqlite3", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	var email, status string
+	if err := db.QueryRow(`SELECT email, status FROM member_list`).Scan(&email, &status); err != nil {
+		t.Fatal(err)
+	}
+	if email != "a@example.com" || status != "active" {
+		t.Errorf("Unexpected row: %s %s", email, status)
+	}
+}
@@ -0,0 +1,350 @@
+package publisher
+
+import (
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cihub/seelog"
+)
+
+const (
+	xlsxDefaultSheetName = "Report"
+	xlsxMaxSheetName     = 31
+
+	// Style index of cellXfs in xlsxStyles
+	xlsxStyleHeader = 1
+	xlsxStyleDate   = 2
+
+	// Type of columns
+	xlsxColumnText   = 0
+	xlsxColumnNumber = 1
+	xlsxColumnTime   = 2
+)
+
+var (
+	// Numbers with leading zeros, or too many digits for Excel are kept as text.
+	xlsxNumberPattern = regexp.MustCompile(`^-?(0|[1-9][0-9]{0,14})(\.[0-9]{1,6})?$`)
+
+	xlsxTimeLayouts = []string{
+		"2006-01-02 15:04:05.999999999 -0700 MST",
+		time.RFC3339Nano,
+		"2006-01-02",
+	}
+
+	// Columns written as numbers. Other columns such as ids are kept as text even
+	// if values look like numbers.
+	xlsxNumberHeaders = map[string]bool{
+		"usage":              true,
+		"allocated":          true,
+		"quota-cap":          true,
+		"percent-used":       true,
+		"size":               true,
+		"file-count":         true,
+		"member-count":       true,
+		"group-member-count": true,
+		"days-inactive":      true,
+		"days-in-state":      true,
+		"total-usage":        true,
+		"shared-usage":       true,
+		"unshared-usage":     true,
+		"shared-folders":     true,
+		"adds":               true,
+		"edits":              true,
+		"deletes":            true,
+		"team-size":          true,
+		"pending-invites":    true,
+		"members-joined":     true,
+		"suspended-members":  true,
+		"licenses":           true,
+	}
+	xlsxNumberHeaderPrefixes = []string{
+		"active-",
+		"shared-links-",
+	}
+
+	// Columns written as date and time.
+	xlsxTimeHeaders = map[string]bool{
+		"date":            true,
+		"timestamp":       true,
+		"created":         true,
+		"updated":         true,
+		"expires":         true,
+		"linked":          true,
+		"server-modified": true,
+		"client-modified": true,
+		"last-seen":       true,
+		"joined-on":       true,
+		"invited-on":      true,
+		"state-since":     true,
+	}
+
+	// Characters not allowed in sheet names
+	xlsxSheetNameReplacer = strings.NewReplacer("[", "", "]", "", ":", "", "*", "", "?", "", "/", "", "\\", "")
+
+	// Serial date origin of Excel
+	xlsxEpoch = time.Date(1899, 12, 30, 0, 0, 0, 0, time.UTC)
+)
+
+// XlsxPublisher writes rows into a sheet of an Excel workbook. Values of known number
+// and time columns are written as typed cells. Rows are buffered in a temporary file
+// until Close.
+type XlsxPublisher struct {
+	OutputFile string
+	SheetName  string
+
+	outFile     *os.File
+	rowsFile    *os.File
+	rows        *bufio.Writer
+	numRows     int
+	numColumns  int
+	columnTypes []int
+}
+
+func xlsxColumnType(header string) int {
+	if xlsxTimeHeaders[header] {
+		return xlsxColumnTime
+	}
+	if xlsxNumberHeaders[header] {
+		return xlsxColumnNumber
+	}
+	for _, p := range xlsxNumberHeaderPrefixes {
+		if strings.HasPrefix(header, p) {
+			return xlsxColumnNumber
+		}
+	}
+	return xlsxColumnText
+}
+
+func xlsxColumnName(index int) string {
+	name := ""
+	for index >= 0 {
+		name = string(rune('A'+index%26)) + name
+		index = index/26 - 1
+	}
+	return name
+}
+
+func xlsxEscape(value string) string {
+	var buf bytes.Buffer
+	xml.EscapeText(&buf, []byte(value))
+	return buf.String()
+}
+
+func (x *XlsxPublisher) sheetName() string {
+	name := []rune(xlsxSheetNameReplacer.Replace(x.SheetName))
+	if len(name) > xlsxMaxSheetName {
+		name = name[:xlsxMaxSheetName]
+	}
+	if len(name) == 0 {
+		return xlsxDefaultSheetName
+	}
+	return string(name)
+}
+
+func (x *XlsxPublisher) cell(ref, value string, header bool, columnType int) string {
+	if header {
+		return fmt.Sprintf(`<c r="%s" s="%d" t="inlineStr"><is><t xml:space="preserve">%s</t></is></c>`, ref, xlsxStyleHeader, xlsxEscape(value))
+	}
+	if value == "" {
+		return ""
+	}
+	switch columnType {
+	case xlsxColumnNumber:
+		if xlsxNumberPattern.MatchString(value) {
+			return fmt.Sprintf(`<c r="%s"><v>%s</v></c>`, ref, value)
+		}
+	case xlsxColumnTime:
+		for _, layout := range xlsxTimeLayouts {
+			if t, err := time.Parse(layout, value); err == nil {
+				serial := t.Sub(xlsxEpoch).Hours() / 24
+				return fmt.Sprintf(`<c r="%s" s="%d"><v>%s</v></c>`, ref, xlsxStyleDate, strconv.FormatFloat(serial, 'f', -1, 64))
+			}
+		}
+	}
+	return fmt.Sprintf(`<c r="%s" t="inlineStr"><is><t xml:space="preserve">%s</t></is></c>`, ref, xlsxEscape(value))
+}
+
+func (x *XlsxPublisher) writeRow(data []string, header bool) error {
+	x.numRows++
+	if len(data) > x.numColumns {
+		x.numColumns = len(data)
+	}
+	fmt.Fprintf(x.rows, `<row r="%d">`, x.numRows)
+	for i, v := range data {
+		ref := fmt.Sprintf("%s%d", xlsxColumnName(i), x.numRows)
+		columnType := xlsxColumnText
+		if i < len(x.columnTypes) {
+			columnType = x.columnTypes[i]
+		}
+		x.rows.WriteString(x.cell(ref, v, header, columnType))
+	}
+	_, err := x.rows.WriteString("</row>\n")
+	return err
+}
+
+func (x *XlsxPublisher) Headers(headers []string) error {
+	x.columnTypes = make([]int, len(headers))
+	for i, h := range headers {
+		x.columnTypes[i] = xlsxColumnType(h)
+	}
+	return x.writeRow(headers, true)
+}
+
+func (x *XlsxPublisher) Row(data []string) error {
+	return x.writeRow(data, false)
+}
+
+func (x *XlsxPublisher) Open() error {
+	out, err := os.Create(x.OutputFile)
+	if err != nil {
+		seelog.Errorf("Unable to create file: '%s'", x.OutputFile)
+		return err
+	}
+	rows, err := ioutil.TempFile("", "dreport-xlsx")
+	if err != nil {
+		out.Close()
+		seelog.Error("Unable to create temporary file", err)
+		return err
+	}
+	x.outFile = out
+	x.rowsFile = rows
+	x.rows = bufio.NewWriter(rows)
+	x.numRows = 0
+	x.numColumns = 0
+	return nil
+}
+
+func (x *XlsxPublisher) writeWorkbook() error {
+	if err := x.rows.Flush(); err != nil {
+		return err
+	}
+	if _, err := x.rowsFile.Seek(0, 0); err != nil {
+		return err
+	}
+
+	z := zip.NewWriter(x.outFile)
+	entries := []struct {
+		name    string
+		content string
+	}{
+		{"[Content_Types].xml", xlsxContentTypes},
+		{"_rels/.rels", xlsxRels},
+		{"xl/workbook.xml", x.workbook()},
+		{"xl/_rels/workbook.xml.rels", xlsxWorkbookRels},
+		{"xl/styles.xml", xlsxStyles},
+	}
+	for _, e := range entries {
+		w, err := z.Create(e.name)
+		if err != nil {
+			return err
+		}
+		if _, err := io.WriteString(w, e.content); err != nil {
+			return err
+		}
+	}
+
+	w, err := z.Create("xl/worksheets/sheet1.xml")
+	if err != nil {
+		return err
+	}
+	io.WriteString(w, xml.Header)
+	io.WriteString(w, `<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">`)
+	if x.numRows > 0 {
+		io.WriteString(w, `<sheetViews><sheetView workbookViewId="0"><pane ySplit="1" topLeftCell="A2" activePane="bottomLeft" state="frozen"/></sheetView></sheetViews>`)
+	}
+	io.WriteString(w, `<sheetData>`)
+	if _, err := io.Copy(w, x.rowsFile); err != nil {
+		return err
+	}
+	io.WriteString(w, `</sheetData>`)
+	if x.hasFilter() {
+		fmt.Fprintf(w, `<autoFilter ref="A1:%s%d"/>`, xlsxColumnName(x.numColumns-1), x.numRows)
+	}
+	io.WriteString(w, `</worksheet>`)
+
+	return z.Close()
+}
+
+func (x *XlsxPublisher) hasFilter() bool {
+	return x.numRows > 0 && x.numColumns > 0
+}
+
+func (x *XlsxPublisher) workbook() string {
+	definedNames := ""
+	if x.hasFilter() {
+		definedNames = fmt.Sprintf(`<definedNames><definedName name="_xlnm._FilterDatabase" localSheetId="0" hidden="1">'%s'!$A$1:$%s$%d</definedName></definedNames>`,
+			xlsxEscape(strings.Replace(x.sheetName(), "'", "''", -1)),
+			xlsxColumnName(x.numColumns-1),
+			x.numRows,
+		)
+	}
+	return xml.Header +
+		`<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">` +
+		fmt.Sprintf(`<sheets><sheet name="%s" sheetId="1" r:id="rId1"/></sheets>`, xlsxEscape(x.sheetName())) +
+		definedNames +
+		`</workbook>`
+}
+
+func (x *XlsxPublisher) Close() {
+	if x.rows != nil {
+		if err := x.writeWorkbook(); err != nil {
+			seelog.Errorf("Unable to write workbook: '%s'", x.OutputFile)
+		}
+		x.rows = nil
+	}
+	if x.rowsFile != nil {
+		x.rowsFile.Close()
+		os.Remove(x.rowsFile.Name())
+		x.rowsFile = nil
+	}
+	if x.outFile != nil {
+		x.outFile.Close()
+		x.outFile = nil
+	}
+}
+
+const (
+	xlsxContentTypes = xml.Header +
+		`<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">` +
+		`<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>` +
+		`<Default Extension="xml" ContentType="application/xml"/>` +
+		`<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>` +
+		`<Override PartName="/xl/worksheets/sheet1.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>` +
+		`<Override PartName="/xl/styles.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.styles+xml"/>` +
+		`</Types>`
+
+	xlsxRels = xml.Header +
+		`<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+		`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/>` +
+		`</Relationships>`
+
+	xlsxWorkbookRels = xml.Header +
+		`<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+		`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet1.xml"/>` +
+		`<Relationship Id="rId2" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/styles" Target="styles.xml"/>` +
+		`</Relationships>`
+
+	xlsxStyles = xml.Header +
+		`<styleSheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">` +
+		`<numFmts count="1"><numFmt numFmtId="164" formatCode="yyyy-mm-dd hh:mm:ss"/></numFmts>` +
+		`<fonts count="2"><font><sz val="11"/><name val="Calibri"/></font><font><b/><sz val="11"/><name val="Calibri"/></font></fonts>` +
+		`<fills count="2"><fill><patternFill patternType="none"/></fill><fill><patternFill patternType="gray125"/></fill></fills>` +
+		`<borders count="1"><border><left/><right/><top/><bottom/><diagonal/></border></borders>` +
+		`<cellStyleXfs count="1"><xf numFmtId="0" fontId="0" fillId="0" borderId="0"/></cellStyleXfs>` +
+		`<cellXfs count="3">` +
+		`<xf numFmtId="0" fontId="0" fillId="0" borderId="0" xfId="0"/>` +
+		`<xf numFmtId="0" fontId="1" fillId="0" borderId="0" xfId="0" applyFont="1"/>` +
+		`<xf numFmtId="164" fontId="0" fillId="0" borderId="0" xfId="0" applyNumberFormat="1"/>` +
+		`</cellXfs>` +
+		`</styleSheet>`
+)
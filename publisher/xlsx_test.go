@@ -0,0 +1,61 @@
+package publisher
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestXlsxColumnType(t *testing.T) {
+	types := map[string]int{
+		"usage":            xlsxColumnNumber,
+		"file-count":       xlsxColumnNumber,
+		"active-users-28d": xlsxColumnNumber,
+		"joined-on":        xlsxColumnTime,
+		"team-member-id":   xlsxColumnText,
+		"external-id":      xlsxColumnText,
+	}
+	for h, expected := range types {
+		if c := xlsxColumnType(h); c != expected {
+			t.Errorf("Unexpected type of '%s': %d", h, c)
+		}
+	}
+}
+
+func TestXlsxCell(t *testing.T) {
+	x := &XlsxPublisher{}
+
+	if c := x.cell("A2", "12345", false, xlsxColumnText); !strings.Contains(c, `t="inlineStr"`) {
+		t.Errorf("Id like value should be a string: %s", c)
+	}
+	if c := x.cell("A2", "12345", false, xlsxColumnNumber); c != `<c r="A2"><v>12345</v></c>` {
+		t.Errorf("Unexpected number cell: %s", c)
+	}
+	if c := x.cell("A2", "n/a", false, xlsxColumnNumber); !strings.Contains(c, `t="inlineStr"`) {
+		t.Errorf("Non numeric value should be a string: %s", c)
+	}
+	if c := x.cell("A2", "2017-01-02 00:00:00 +0000 UTC", false, xlsxColumnTime); c != `<c r="A2" s="2"><v>42737</v></c>` {
+		t.Errorf("Unexpected time cell: %s", c)
+	}
+	if c := x.cell("A2", "2017-01-02", false, xlsxColumnText); !strings.Contains(c, `t="inlineStr"`) {
+		t.Errorf("Date in text column should be a string: %s", c)
+	}
+	if c := x.cell("A2", "", false, xlsxColumnNumber); c != "" {
+		t.Errorf("Empty value should be omitted: %s", c)
+	}
+}
+
+func TestXlsxSheetName(t *testing.T) {
+	names := map[string]string{
+		"":                      xlsxDefaultSheetName,
+		"members":               "members",
+		"a/b:c[d]*?\\":          "abcd",
+		"[]":                    xlsxDefaultSheetName,
+		strings.Repeat("あ", 40): strings.Repeat("あ", 31),
+	}
+	for n, expected := range names {
+		x := &XlsxPublisher{SheetName: n}
+		if s := x.sheetName(); s != expected {
+			t.Errorf("Unexpected sheet name of '%s': '%s'", n, s)
+		}
+	}
+}
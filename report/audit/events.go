@@ -0,0 +1,154 @@
+package audit
+
+import (
+	"flag"
+	"strconv"
+	"strings"
+
+	"github.com/watermint/dreport/auth"
+	"github.com/watermint/dreport/crawler"
+	"github.com/watermint/dreport/integration"
+)
+
+type ReportTeamAuditEvents struct {
+	Categories string
+}
+
+func (t *ReportTeamAuditEvents) ReportName() string {
+	return "TeamAuditEvents"
+}
+
+func (t *ReportTeamAuditEvents) ReportDescription() string {
+	return "List events of the team event log"
+}
+
+func (t *ReportTeamAuditEvents) RequiredPermissions() []string {
+	return []string{auth.PERMISSION_AUDIT}
+}
+
+func (t *ReportTeamAuditEvents) DefineOptions(f *flag.FlagSet) {
+	f.StringVar(&t.Categories, "audit-category", "", "Event categories of TeamAuditEvents (comma separated, e.g. logins,sharing)")
+}
+
+func (t *ReportTeamAuditEvents) Report(context *integration.ReportContext) error {
+	context.ReportOutput.Headers(t.createHeader())
+
+	handler := func(e *crawler.TeamEvent) error {
+		return context.ReportOutput.Row(t.createRow(e))
+	}
+
+	categories := t.categories()
+	if len(categories) == 0 {
+		return crawler.AllTeamEvents(context, "", handler)
+	}
+	for _, c := range categories {
+		if err := crawler.AllTeamEvents(context, c, handler); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (t *ReportTeamAuditEvents) categories() []string {
+	categories := make([]string, 0)
+	for _, c := range strings.Split(t.Categories, ",") {
+		c = strings.TrimSpace(c)
+		if c != "" {
+			categories = append(categories, c)
+		}
+	}
+	return categories
+}
+
+func (t *ReportTeamAuditEvents) createHeader() []string {
+	return []string{
+		"timestamp",
+		"event-category",
+		"event-type",
+		"event-description",
+		"actor-type",
+		"actor-email",
+		"actor-name",
+		"context-type",
+		"context-email",
+		"ip-address",
+		"country",
+		"access-method",
+		"involve-non-team-member",
+		"assets",
+		"details",
+	}
+}
+
+func (t *ReportTeamAuditEvents) createRow(e *crawler.TeamEvent) []string {
+	category := ""
+	if e.EventCategory != nil {
+		category = e.EventCategory.Tag
+	}
+	eventType := ""
+	eventDescription := ""
+	if e.EventType != nil {
+		eventType = e.EventType.Tag
+		eventDescription = e.EventType.Description
+	}
+
+	actorType := ""
+	actorEmail := ""
+	actorName := ""
+	if e.Actor != nil {
+		actorType = e.Actor.Tag
+		switch {
+		case e.Actor.User != nil:
+			actorEmail = e.Actor.User.Email
+			actorName = e.Actor.User.DisplayName
+		case e.Actor.Admin != nil:
+			actorEmail = e.Actor.Admin.Email
+			actorName = e.Actor.Admin.DisplayName
+		case e.Actor.App != nil:
+			actorName = e.Actor.App.DisplayName
+		}
+	}
+
+	contextType := ""
+	contextEmail := ""
+	if e.Context != nil {
+		contextType = e.Context.Tag
+		contextEmail = e.Context.Email
+	}
+
+	ipAddress := ""
+	country := ""
+	accessMethod := ""
+	if e.Origin != nil {
+		if e.Origin.GeoLocation != nil {
+			ipAddress = e.Origin.GeoLocation.IpAddress
+			country = e.Origin.GeoLocation.Country
+		}
+		if e.Origin.AccessMethod != nil {
+			accessMethod = e.Origin.AccessMethod.Tag
+		}
+	}
+
+	assets := make([]string, 0, len(e.Assets))
+	for _, a := range e.Assets {
+		assets = append(assets, a.DisplayName)
+	}
+
+	return []string{
+		e.Timestamp.String(),
+		category,
+		eventType,
+		eventDescription,
+		actorType,
+		actorEmail,
+		actorName,
+		contextType,
+		contextEmail,
+		ipAddress,
+		country,
+		accessMethod,
+		strconv.FormatBool(e.InvolveNonTeamMember),
+		strings.Join(assets, ";"),
+		string(e.Details),
+	}
+}
@@ -0,0 +1,141 @@
+package group
+
+import (
+	"github.com/cihub/seelog"
+	"github.com/dropbox/dropbox-sdk-go-unofficial/team"
+	"github.com/dropbox/dropbox-sdk-go-unofficial/team_common"
+	"github.com/watermint/dreport/auth"
+	"github.com/watermint/dreport/crawler"
+	"github.com/watermint/dreport/integration"
+	"strconv"
+)
+
+type ReportGroupMembers struct {
+}
+
+func (t *ReportGroupMembers) ReportName() string {
+	return "TeamGroupMembers"
+}
+
+func (t *ReportGroupMembers) ReportDescription() string {
+	return "List all groups and their members of a team"
+}
+
+func (t *ReportGroupMembers) RequiredPermissions() []string {
+	return []string{auth.PERMISSION_INFO}
+}
+
+func (t *ReportGroupMembers) Resumable() bool {
+	return true
+}
+
+func (t *ReportGroupMembers) Report(rc *integration.ReportContext) error {
+	// Load members before groups to fail early
+	if _, err := rc.Members.Members(); err != nil {
+		return err
+	}
+	groups, err := crawler.AllGroups(rc)
+	if err != nil {
+		return err
+	}
+
+	rc.ReportOutput.Headers(t.createHeader())
+
+	loadGroupMembers := func(i int) (interface{}, error) {
+		g := groups[i]
+		if rc.Checkpoint.IsDone("group-members/" + g.GroupId) {
+			return nil, nil
+		}
+		return crawler.AllGroupMembers(rc, g.GroupId)
+	}
+	writeGroupMembers := func(i int, groupMembers interface{}, err error) error {
+		g := groups[i]
+		if err != nil {
+			seelog.Warnf("Unable to load group members for group '%s'", g.GroupId)
+			return rc.HandleError(g.GroupId, err)
+		}
+		if groupMembers == nil {
+			return nil
+		}
+		gm := groupMembers.([]*team.GroupMemberInfo)
+		if len(gm) < 1 {
+			if err := rc.ReportOutput.Row(t.createGroupRow(g)); err != nil {
+				return err
+			}
+		}
+		for _, m := range gm {
+			member, _ := rc.Members.ByTeamMemberId(m.Profile.TeamMemberId)
+			if err := rc.ReportOutput.Row(t.createMemberRow(g, m, member)); err != nil {
+				return err
+			}
+		}
+		rc.Checkpoint.Done("group-members/" + g.GroupId)
+		return rc.Checkpoint.Commit()
+	}
+
+	return crawler.ForEachOrdered(len(groups), rc.Concurrency, loadGroupMembers, writeGroupMembers)
+}
+
+func (t *ReportGroupMembers) createHeader() []string {
+	return []string{
+		"group-id",
+		"group-name",
+		"group-external-id",
+		"group-management-type",
+		"group-member-count",
+		"account-id",
+		"team-member-id",
+		"email",
+		"status",
+		"role",
+		"group-access-type",
+	}
+}
+
+func (t *ReportGroupMembers) groupColumns(g *team_common.GroupSummary) []string {
+	managementType := ""
+	if g.GroupManagementType != nil {
+		managementType = g.GroupManagementType.Tag
+	}
+	return []string{
+		g.GroupId,
+		g.GroupName,
+		g.GroupExternalId,
+		managementType,
+		strconv.FormatUint(uint64(g.MemberCount), 10),
+	}
+}
+
+// Row for the group without members.
+func (t *ReportGroupMembers) createGroupRow(g *team_common.GroupSummary) []string {
+	return append(t.groupColumns(g),
+		"", // account-id
+		"", // team-member-id
+		"", // email
+		"", // status
+		"", // role
+		"", // group-access-type
+	)
+}
+
+func (t *ReportGroupMembers) createMemberRow(g *team_common.GroupSummary, m *team.GroupMemberInfo, member *team.TeamMemberInfo) []string {
+	email := m.Profile.Email
+	status := ""
+	if m.Profile.Status != nil {
+		status = m.Profile.Status.Tag
+	}
+	role := ""
+	if member != nil {
+		email = member.Profile.Email
+		status = member.Profile.Status.Tag
+		role = member.Role.Tag
+	}
+	return append(t.groupColumns(g),
+		m.Profile.AccountId,
+		m.Profile.TeamMemberId,
+		email,
+		status,
+		role,
+		m.AccessType.Tag,
+	)
+}
@@ -0,0 +1,193 @@
+package member
+
+import (
+	"flag"
+	"github.com/cihub/seelog"
+	"github.com/dropbox/dropbox-sdk-go-unofficial"
+	"github.com/dropbox/dropbox-sdk-go-unofficial/team"
+	"github.com/watermint/dreport/auth"
+	"github.com/watermint/dreport/integration"
+	"sort"
+	"strconv"
+)
+
+type ReportTeamLinkedApps struct {
+	Aggregate bool
+}
+
+// Number of members linked to the app.
+type linkedAppUsage struct {
+	app     *team.ApiApp
+	members int
+}
+
+type linkedAppUsages []*linkedAppUsage
+
+func (u linkedAppUsages) Len() int {
+	return len(u)
+}
+
+func (u linkedAppUsages) Less(i, j int) bool {
+	if u[i].members != u[j].members {
+		return u[i].members > u[j].members
+	}
+	return u[i].app.AppId < u[j].app.AppId
+}
+
+func (u linkedAppUsages) Swap(i, j int) {
+	u[i], u[j] = u[j], u[i]
+}
+
+func (t *ReportTeamLinkedApps) ReportName() string {
+	return "TeamLinkedApps"
+}
+
+func (t *ReportTeamLinkedApps) ReportDescription() string {
+	return "List third-party apps linked by all team member of a team"
+}
+
+func (t *ReportTeamLinkedApps) RequiredPermissions() []string {
+	return []string{
+		auth.PERMISSION_INFO,
+		auth.PERMISSION_FILE,
+	}
+}
+
+func (t *ReportTeamLinkedApps) Resumable() bool {
+	return true
+}
+
+func (t *ReportTeamLinkedApps) DefineOptions(f *flag.FlagSet) {
+	f.BoolVar(&t.Aggregate, "apps-aggregate", false, "Count members per app for TeamLinkedApps, instead of listing apps per member")
+}
+
+func (t *ReportTeamLinkedApps) Report(context *integration.ReportContext) error {
+	if _, err := context.Members.Members(); err != nil {
+		seelog.Errorf("Unable to load member list: %v", err)
+		return err
+	}
+
+	fileClient := dropbox.Client(context.TeamFileToken, dropbox.Options{})
+
+	if t.Aggregate {
+		context.ReportOutput.Headers(t.createAggregateHeader())
+	} else {
+		context.ReportOutput.Headers(t.createHeader())
+	}
+
+	usages := make(map[string]*linkedAppUsage)
+
+	seelog.Info("Loading linked apps")
+	query := team.NewListMembersAppsArg()
+	var cursor string
+	if !t.Aggregate && context.Checkpoint.Get("linked-apps", &cursor) {
+		seelog.Info("Resume loading linked apps")
+		query.Cursor = cursor
+	}
+	apps, err := fileClient.LinkedAppsListMembersLinkedApps(query)
+	if err != nil {
+		seelog.Error("Unable to load linked apps", err)
+		return err
+	}
+	for {
+		for _, ma := range apps.Apps {
+			member, found := context.Members.ByTeamMemberId(ma.TeamMemberId)
+			if !found {
+				seelog.Errorf("Member profile not found for Team Member Id: %s", ma.TeamMemberId)
+				continue
+			}
+			for _, a := range ma.LinkedApiApps {
+				if t.Aggregate {
+					if u, ok := usages[a.AppId]; ok {
+						u.members++
+					} else {
+						usages[a.AppId] = &linkedAppUsage{app: a, members: 1}
+					}
+					continue
+				}
+				context.ReportOutput.Row(t.createRow(member, a))
+			}
+		}
+		if !apps.HasMore {
+			break
+		}
+		if !t.Aggregate {
+			context.Checkpoint.Set("linked-apps", apps.Cursor)
+			if err := context.Checkpoint.Commit(); err != nil {
+				return err
+			}
+		}
+
+		seelog.Info("Loading more linked apps..")
+		query = team.NewListMembersAppsArg()
+		query.Cursor = apps.Cursor
+		apps, err = fileClient.LinkedAppsListMembersLinkedApps(query)
+		if err != nil {
+			seelog.Error("Unable to load linked apps (continue)", err)
+			return err
+		}
+	}
+
+	if t.Aggregate {
+		sorted := make(linkedAppUsages, 0, len(usages))
+		for _, u := range usages {
+			sorted = append(sorted, u)
+		}
+		sort.Sort(sorted)
+		for _, u := range sorted {
+			context.ReportOutput.Row(t.createAggregateRow(u))
+		}
+	}
+	seelog.Info("Finished")
+	return nil
+}
+
+func (t *ReportTeamLinkedApps) createHeader() []string {
+	return []string{
+		"account-id",
+		"team-member-id",
+		"email",
+		"app-id",
+		"app-name",
+		"publisher",
+		"publisher-url",
+		"is-app-folder",
+		"linked",
+	}
+}
+
+func (t *ReportTeamLinkedApps) createRow(member *team.TeamMemberInfo, a *team.ApiApp) []string {
+	return []string{
+		member.Profile.AccountId,
+		member.Profile.TeamMemberId,
+		member.Profile.Email,
+		a.AppId,
+		a.AppName,
+		a.Publisher,
+		a.PublisherUrl,
+		strconv.FormatBool(a.IsAppFolder),
+		a.Linked.String(),
+	}
+}
+
+func (t *ReportTeamLinkedApps) createAggregateHeader() []string {
+	return []string{
+		"app-id",
+		"app-name",
+		"publisher",
+		"publisher-url",
+		"is-app-folder",
+		"member-count",
+	}
+}
+
+func (t *ReportTeamLinkedApps) createAggregateRow(u *linkedAppUsage) []string {
+	return []string{
+		u.app.AppId,
+		u.app.AppName,
+		u.app.Publisher,
+		u.app.PublisherUrl,
+		strconv.FormatBool(u.app.IsAppFolder),
+		strconv.Itoa(u.members),
+	}
+}
@@ -0,0 +1,226 @@
+package member
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"flag"
+	"github.com/cihub/seelog"
+	"github.com/dropbox/dropbox-sdk-go-unofficial/team"
+	"github.com/watermint/dreport/auth"
+	"github.com/watermint/dreport/crawler"
+	"github.com/watermint/dreport/integration"
+	"io"
+	"io/ioutil"
+	"os"
+	"strconv"
+	"strings"
+)
+
+type ReportMemberFiles struct {
+	Members string
+}
+
+func (t *ReportMemberFiles) ReportName() string {
+	return "MemberFiles"
+}
+
+func (t *ReportMemberFiles) ReportDescription() string {
+	return "List all files of team members of a team"
+}
+
+func (t *ReportMemberFiles) RequiredPermissions() []string {
+	return []string{
+		auth.PERMISSION_INFO,
+		auth.PERMISSION_FILE,
+	}
+}
+
+func (t *ReportMemberFiles) Resumable() bool {
+	return true
+}
+
+func (t *ReportMemberFiles) DefineOptions(f *flag.FlagSet) {
+	f.StringVar(&t.Members, "member", "", "Email addresses of members to list files of MemberFiles (comma separated, default all members)")
+}
+
+// Target members of the report.
+func (t *ReportMemberFiles) targetMembers(rc *integration.ReportContext) ([]*team.TeamMemberInfo, error) {
+	if t.Members == "" {
+		return rc.Members.Members()
+	}
+	members := make([]*team.TeamMemberInfo, 0)
+	for _, email := range strings.Split(t.Members, ",") {
+		email = strings.TrimSpace(email)
+		if email == "" {
+			continue
+		}
+		m, found := rc.Members.ByEmail(email)
+		if !found {
+			seelog.Errorf("Member not found: '%s'", email)
+			return nil, errors.New("Member not found: " + email)
+		}
+		members = append(members, m)
+	}
+	return members, nil
+}
+
+func (t *ReportMemberFiles) Report(rc *integration.ReportContext) error {
+	members, err := t.targetMembers(rc)
+	if err != nil {
+		return err
+	}
+	rc.ReportOutput.Headers(t.createHeader())
+
+	// Workers spool pages of each member into a temporary file, and pages are written
+	// in the order of members. Thus rows of members are not mixed, no rows are written
+	// for skipped members, and files of members are not held in memory.
+	spoolDir, err := ioutil.TempDir("", "dreport")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(spoolDir)
+
+	listFiles := func(i int) (interface{}, error) {
+		m := members[i]
+		key := "member-files/" + m.Profile.TeamMemberId
+		if rc.Checkpoint.IsDone(key) {
+			return nil, nil
+		}
+		cursor := ""
+		if rc.Checkpoint.Get(key, &cursor) {
+			seelog.Infof("Resume listing files of member '%s'", m.Profile.Email)
+		} else {
+			seelog.Infof("Listing files of member '%s'", m.Profile.Email)
+		}
+		return t.spoolPages(spoolDir, func(handler func(entries []*crawler.FileEntry, cursor string) error) error {
+			return crawler.ListFolderPages(rc.TeamFileToken, m.Profile.TeamMemberId, "", cursor, handler)
+		})
+	}
+	writeFiles := func(i int, spool interface{}, err error) error {
+		m := members[i]
+		if err != nil {
+			return rc.HandleError(m.Profile.TeamMemberId, err)
+		}
+		if spool == nil {
+			return nil
+		}
+		key := "member-files/" + m.Profile.TeamMemberId
+		err = t.readPages(spool.(string), func(page *memberFilesPage) error {
+			for _, e := range page.Files {
+				if err := rc.ReportOutput.Row(t.createRow(m, e)); err != nil {
+					return err
+				}
+			}
+			// Listing continues from the cursor of the next page on resume
+			rc.Checkpoint.Set(key, page.Cursor)
+			return rc.Checkpoint.Commit()
+		})
+		if err != nil {
+			return err
+		}
+		rc.Checkpoint.Done(key)
+		return rc.Checkpoint.Commit()
+	}
+
+	return crawler.ForEachOrdered(len(members), rc.Concurrency, listFiles, writeFiles)
+}
+
+// Files of the page, with the cursor of the next page.
+type memberFilesPage struct {
+	Files  []*crawler.FileEntry `json:"files"`
+	Cursor string               `json:"cursor"`
+}
+
+// Spool pages of the listing into a temporary file under the dir, and returns the path
+// of the file. The file is removed if the listing failed.
+func (t *ReportMemberFiles) spoolPages(dir string, list func(handler func(entries []*crawler.FileEntry, cursor string) error) error) (string, error) {
+	f, err := ioutil.TempFile(dir, "member-files")
+	if err != nil {
+		return "", err
+	}
+	w := bufio.NewWriter(f)
+	enc := json.NewEncoder(w)
+	err = list(func(entries []*crawler.FileEntry, cursor string) error {
+		page := &memberFilesPage{
+			Files:  make([]*crawler.FileEntry, 0, len(entries)),
+			Cursor: cursor,
+		}
+		for _, e := range entries {
+			if e.Tag == "file" {
+				page.Files = append(page.Files, e)
+			}
+		}
+		return enc.Encode(page)
+	})
+	if err == nil {
+		err = w.Flush()
+	}
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		os.Remove(f.Name())
+		return "", err
+	}
+	return f.Name(), nil
+}
+
+// Read pages of the spool file in order, and remove the file.
+func (t *ReportMemberFiles) readPages(path string, handler func(page *memberFilesPage) error) error {
+	defer os.Remove(path)
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	dec := json.NewDecoder(bufio.NewReader(f))
+	for {
+		page := &memberFilesPage{}
+		if err := dec.Decode(page); err == io.EOF {
+			return nil
+		} else if err != nil {
+			return err
+		}
+		if err := handler(page); err != nil {
+			return err
+		}
+	}
+}
+
+func (t *ReportMemberFiles) createHeader() []string {
+	return []string{
+		"team-member-id",
+		"email",
+		"path",
+		"name",
+		"size",
+		"server-modified",
+		"client-modified",
+		"content-hash",
+		"rev",
+		"file-id",
+		"shared-folder-id",
+	}
+}
+
+func (t *ReportMemberFiles) createRow(member *team.TeamMemberInfo, e *crawler.FileEntry) []string {
+	sharedFolderId := ""
+	if e.SharingInfo != nil {
+		sharedFolderId = e.SharingInfo.ParentSharedFolderId
+	}
+	return []string{
+		member.Profile.TeamMemberId,
+		member.Profile.Email,
+		e.PathDisplay,
+		e.Name,
+		strconv.FormatUint(e.Size, 10),
+		e.ServerModified.String(),
+		e.ClientModified.String(),
+		e.ContentHash,
+		e.Rev,
+		e.Id,
+		sharedFolderId,
+	}
+}
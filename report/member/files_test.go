@@ -0,0 +1,74 @@
+package member
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/watermint/dreport/crawler"
+)
+
+func TestMemberFilesSpool(t *testing.T) {
+	dir, err := ioutil.TempDir("", "dreport")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	r := &ReportMemberFiles{}
+	path, err := r.spoolPages(dir, func(handler func(entries []*crawler.FileEntry, cursor string) error) error {
+		if err := handler([]*crawler.FileEntry{{Tag: "folder", Name: "a"}, {Tag: "file", Name: "b"}}, "c1"); err != nil {
+			return err
+		}
+		return handler([]*crawler.FileEntry{{Tag: "file", Name: "c"}}, "c2")
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	names := make([]string, 0)
+	cursors := make([]string, 0)
+	err = r.readPages(path, func(page *memberFilesPage) error {
+		for _, e := range page.Files {
+			names = append(names, e.Name)
+		}
+		cursors = append(cursors, page.Cursor)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(names) != 2 || names[0] != "b" || names[1] != "c" {
+		t.Errorf("Unexpected files: %v", names)
+	}
+	if len(cursors) != 2 || cursors[0] != "c1" || cursors[1] != "c2" {
+		t.Errorf("Unexpected cursors: %v", cursors)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Error("Spool file should be removed")
+	}
+}
+
+func TestMemberFilesSpoolFailure(t *testing.T) {
+	dir, err := ioutil.TempDir("", "dreport")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	failure := errors.New("failure")
+	r := &ReportMemberFiles{}
+	_, err = r.spoolPages(dir, func(handler func(entries []*crawler.FileEntry, cursor string) error) error {
+		if err := handler([]*crawler.FileEntry{{Tag: "file", Name: "a"}}, "c1"); err != nil {
+			return err
+		}
+		return failure
+	})
+	if err != failure {
+		t.Errorf("Error should be returned: %v", err)
+	}
+	if files, _ := ioutil.ReadDir(dir); len(files) != 0 {
+		t.Errorf("Spool file should be removed: %d", len(files))
+	}
+}
@@ -0,0 +1,208 @@
+package member
+
+import (
+	"flag"
+	"github.com/cihub/seelog"
+	"github.com/dropbox/dropbox-sdk-go-unofficial"
+	"github.com/dropbox/dropbox-sdk-go-unofficial/team"
+	"github.com/dropbox/dropbox-sdk-go-unofficial/users"
+	"github.com/watermint/dreport/auth"
+	"github.com/watermint/dreport/crawler"
+	"github.com/watermint/dreport/integration"
+	"strconv"
+	"time"
+)
+
+const (
+	DEFAULT_INACTIVE_DAYS = 90
+)
+
+type ReportInactiveMembers struct {
+	InactiveDays int
+}
+
+// The last activity of the member, and the source of the activity.
+type lastActivity struct {
+	seen   time.Time
+	source string
+}
+
+type inactiveMember struct {
+	member   *team.TeamMemberInfo
+	activity *lastActivity
+}
+
+func (t *ReportInactiveMembers) ReportName() string {
+	return "InactiveMembers"
+}
+
+func (t *ReportInactiveMembers) ReportDescription() string {
+	return "List active team members without activity in the last N days"
+}
+
+func (t *ReportInactiveMembers) RequiredPermissions() []string {
+	return []string{
+		auth.PERMISSION_INFO,
+		auth.PERMISSION_FILE,
+	}
+}
+
+func (t *ReportInactiveMembers) Resumable() bool {
+	return true
+}
+
+func (t *ReportInactiveMembers) OptionalPermissions() []string {
+	return []string{auth.PERMISSION_AUDIT}
+}
+
+func (t *ReportInactiveMembers) DefineOptions(f *flag.FlagSet) {
+	f.IntVar(&t.InactiveDays, "inactive-days", DEFAULT_INACTIVE_DAYS, "Days without activity to list members in InactiveMembers")
+}
+
+func (t *ReportInactiveMembers) Report(rc *integration.ReportContext) error {
+	members, err := rc.Members.Members()
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	threshold := now.AddDate(0, 0, -t.InactiveDays)
+
+	activities := make(map[string]*lastActivity)
+	seen := func(teamMemberId string, at time.Time, source string) {
+		if a, ok := activities[teamMemberId]; ok && !at.After(a.seen) {
+			return
+		}
+		activities[teamMemberId] = &lastActivity{seen: at, source: source}
+	}
+
+	for _, m := range members {
+		if !m.Profile.JoinedOn.IsZero() {
+			seen(m.Profile.TeamMemberId, m.Profile.JoinedOn, "joined")
+		}
+	}
+
+	// Last activity of sessions
+	err = crawler.AllMemberDevices(rc, func(d *team.MemberDevices) error {
+		for _, s := range d.WebSessions {
+			seen(d.TeamMemberId, s.Updated, "web-session")
+		}
+		for _, s := range d.DesktopClients {
+			seen(d.TeamMemberId, s.Updated, "desktop-client")
+		}
+		for _, s := range d.MobileClients {
+			seen(d.TeamMemberId, s.Updated, "mobile-client")
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	// Logins of the period from the team event log, if the audit token is available.
+	// Logins before the period are not loaded, because members who logged in are
+	// active anyway.
+	if rc.TeamAuditToken != "" {
+		auditContext := *rc
+		auditContext.Since = threshold
+		auditContext.Until = time.Time{}
+		err = crawler.AllTeamEvents(&auditContext, "logins", func(e *crawler.TeamEvent) error {
+			if e.EventType == nil || e.EventType.Tag != "login_success" {
+				return nil
+			}
+			if e.Actor == nil || e.Actor.User == nil || e.Actor.User.TeamMemberId == "" {
+				return nil
+			}
+			seen(e.Actor.User.TeamMemberId, e.Timestamp, "login")
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+	} else {
+		seelog.Info("Audit token is not available. Skip login events")
+	}
+
+	inactiveMembers := make([]*inactiveMember, 0)
+	for _, m := range members {
+		if m.Profile.Status.Tag != "active" {
+			continue
+		}
+		a, ok := activities[m.Profile.TeamMemberId]
+		if ok && a.seen.After(threshold) {
+			continue
+		}
+		inactiveMembers = append(inactiveMembers, &inactiveMember{
+			member:   m,
+			activity: a,
+		})
+	}
+	seelog.Infof("%d inactive member(s) found", len(inactiveMembers))
+
+	rc.ReportOutput.Headers(t.createHeader())
+
+	loadUsage := func(i int) (interface{}, error) {
+		m := inactiveMembers[i].member
+		if rc.Checkpoint.IsDone("inactive-members/" + m.Profile.TeamMemberId) {
+			return nil, nil
+		}
+		memberClient := dropbox.Client(rc.TeamFileToken, dropbox.Options{
+			AsMemberId: m.Profile.TeamMemberId,
+		})
+		usage, err := memberClient.GetSpaceUsage()
+		if err != nil {
+			seelog.Errorf("Unable to load quota for member: '%s'", m.Profile.AccountId)
+			return nil, err
+		}
+		return usage, nil
+	}
+	writeMember := func(i int, usage interface{}, err error) error {
+		im := inactiveMembers[i]
+		if err != nil {
+			return rc.HandleError(im.member.Profile.TeamMemberId, err)
+		}
+		if usage == nil {
+			return nil
+		}
+		if err := rc.ReportOutput.Row(t.createRow(im, usage.(*users.SpaceUsage), now)); err != nil {
+			return err
+		}
+		rc.Checkpoint.Done("inactive-members/" + im.member.Profile.TeamMemberId)
+		return rc.Checkpoint.Commit()
+	}
+
+	return crawler.ForEachOrdered(len(inactiveMembers), rc.Concurrency, loadUsage, writeMember)
+}
+
+func (t *ReportInactiveMembers) createHeader() []string {
+	return []string{
+		"account-id",
+		"team-member-id",
+		"email",
+		"status",
+		"last-seen",
+		"last-seen-source",
+		"days-inactive",
+		"usage",
+	}
+}
+
+func (t *ReportInactiveMembers) createRow(im *inactiveMember, usage *users.SpaceUsage, now time.Time) []string {
+	lastSeen := ""
+	lastSeenSource := ""
+	daysInactive := ""
+	if im.activity != nil {
+		lastSeen = im.activity.seen.String()
+		lastSeenSource = im.activity.source
+		daysInactive = strconv.Itoa(int(now.Sub(im.activity.seen).Hours() / 24))
+	}
+	return []string{
+		im.member.Profile.AccountId,
+		im.member.Profile.TeamMemberId,
+		im.member.Profile.Email,
+		im.member.Profile.Status.Tag,
+		lastSeen,
+		lastSeenSource,
+		daysInactive,
+		strconv.FormatUint(usage.Used, 10),
+	}
+}
@@ -0,0 +1,159 @@
+package member
+
+import (
+	"encoding/json"
+	"github.com/cihub/seelog"
+	"github.com/dropbox/dropbox-sdk-go-unofficial/team"
+	"github.com/watermint/dreport/auth"
+	"github.com/watermint/dreport/crawler"
+	"github.com/watermint/dreport/integration"
+	"strconv"
+	"time"
+)
+
+type ReportMembershipLifecycle struct {
+}
+
+// Change of the member status recorded in the team event log.
+type memberStatusChange struct {
+	at    time.Time
+	actor string
+}
+
+type memberStatusChangeDetails struct {
+	NewValue *crawler.Tagged `json:"new_value"`
+}
+
+func (t *ReportMembershipLifecycle) ReportName() string {
+	return "MembershipLifecycle"
+}
+
+func (t *ReportMembershipLifecycle) ReportDescription() string {
+	return "List invited and suspended team members with how long they have been in the state"
+}
+
+func (t *ReportMembershipLifecycle) RequiredPermissions() []string {
+	return []string{auth.PERMISSION_INFO}
+}
+
+func (t *ReportMembershipLifecycle) OptionalPermissions() []string {
+	return []string{auth.PERMISSION_AUDIT}
+}
+
+func (t *ReportMembershipLifecycle) Report(context *integration.ReportContext) error {
+	members, err := context.Members.Members()
+	if err != nil {
+		return err
+	}
+
+	// Latest status changes of members, keyed by team member id and then new status.
+	changes := make(map[string]map[string]*memberStatusChange)
+	if context.TeamAuditToken != "" {
+		err = crawler.AllTeamEvents(context, "members", func(e *crawler.TeamEvent) error {
+			if e.EventType == nil || e.EventType.Tag != "member_change_status" {
+				return nil
+			}
+			if e.Context == nil || e.Context.TeamMemberId == "" {
+				return nil
+			}
+			details := &memberStatusChangeDetails{}
+			if err := json.Unmarshal(e.Details, details); err != nil || details.NewValue == nil {
+				return nil
+			}
+			memberChanges, ok := changes[e.Context.TeamMemberId]
+			if !ok {
+				memberChanges = make(map[string]*memberStatusChange)
+				changes[e.Context.TeamMemberId] = memberChanges
+			}
+			if c, ok := memberChanges[details.NewValue.Tag]; ok && !e.Timestamp.After(c.at) {
+				return nil
+			}
+			memberChanges[details.NewValue.Tag] = &memberStatusChange{
+				at:    e.Timestamp,
+				actor: t.actorEmail(e.Actor),
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+	} else {
+		seelog.Info("Audit token is not available. Skip status change events")
+	}
+
+	context.ReportOutput.Headers(t.createHeader())
+
+	now := time.Now()
+	for _, m := range members {
+		if m.Profile.Status.Tag == "active" {
+			continue
+		}
+		if err := context.ReportOutput.Row(t.createRow(m, changes[m.Profile.TeamMemberId], now)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (t *ReportMembershipLifecycle) actorEmail(actor *crawler.TeamEventActor) string {
+	switch {
+	case actor == nil:
+		return ""
+	case actor.Admin != nil:
+		return actor.Admin.Email
+	case actor.User != nil:
+		return actor.User.Email
+	}
+	return ""
+}
+
+func (t *ReportMembershipLifecycle) createHeader() []string {
+	return []string{
+		"account-id",
+		"team-member-id",
+		"email",
+		"status",
+		"joined-on",
+		"invited-on",
+		"invited-by",
+		"state-since",
+		"days-in-state",
+	}
+}
+
+func (t *ReportMembershipLifecycle) createRow(member *team.TeamMemberInfo, changes map[string]*memberStatusChange, now time.Time) []string {
+	status := member.Profile.Status.Tag
+
+	joinedOn := ""
+	if !member.Profile.JoinedOn.IsZero() {
+		joinedOn = member.Profile.JoinedOn.String()
+	}
+
+	invitedOn := ""
+	invitedBy := ""
+	if c, ok := changes["invited"]; ok {
+		invitedOn = c.at.String()
+		invitedBy = c.actor
+	}
+
+	// The member is in the state since the latest change to the state. Unknown if
+	// the change is not found in the event log.
+	stateSince := ""
+	daysInState := ""
+	if c, ok := changes[status]; ok {
+		stateSince = c.at.String()
+		daysInState = strconv.Itoa(int(now.Sub(c.at).Hours() / 24))
+	}
+
+	return []string{
+		member.Profile.AccountId,
+		member.Profile.TeamMemberId,
+		member.Profile.Email,
+		status,
+		joinedOn,
+		invitedOn,
+		invitedBy,
+		stateSince,
+		daysInState,
+	}
+}
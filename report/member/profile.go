@@ -1,14 +1,58 @@
 package member
 
 import (
+	"errors"
+	"flag"
 	"github.com/dropbox/dropbox-sdk-go-unofficial/team"
 	"github.com/watermint/dreport/auth"
 	"github.com/watermint/dreport/crawler"
 	"github.com/watermint/dreport/integration"
 	"strconv"
+	"strings"
 )
 
+// Columns appended to the profile only if selected.
+var profileOptionalColumns = []string{
+	"given-name",
+	"surname",
+	"display-name",
+	"joined-on",
+	"groups",
+	"persistent-id",
+	"secondary-emails",
+	"member-folder-id",
+}
+
 type ReportMemberProfile struct {
+	// Optional columns selected, in the order of profileOptionalColumns.
+	Columns []string
+}
+
+// Value of the option of optional columns. Unknown columns are rejected while parsing
+// options, thus before the authorisation.
+type profileColumnsValue struct {
+	value   string
+	columns *[]string
+}
+
+func (v *profileColumnsValue) String() string {
+	return v.value
+}
+
+func (v *profileColumnsValue) Set(value string) error {
+	columns, err := parseProfileColumns(value)
+	if err != nil {
+		return err
+	}
+	v.value = value
+	*v.columns = columns
+	return nil
+}
+
+// Attributes loaded only for optional columns.
+type memberProfileExtras struct {
+	groupNames map[string]string
+	extras     map[string]*crawler.TeamMemberExtra
 }
 
 func (t *ReportMemberProfile) ReportName() string {
@@ -23,16 +67,78 @@ func (t *ReportMemberProfile) RequiredPermissions() []string {
 	return []string{auth.PERMISSION_INFO}
 }
 
+func (t *ReportMemberProfile) DefineOptions(f *flag.FlagSet) {
+	f.Var(&profileColumnsValue{columns: &t.Columns}, "profile-columns", "Optional columns of TeamMemberProfile (comma separated, or 'all'): "+strings.Join(profileOptionalColumns, ","))
+}
+
+// Parse comma separated optional columns, and returns columns in the order of
+// profileOptionalColumns.
+func parseProfileColumns(value string) ([]string, error) {
+	if value == "all" {
+		return profileOptionalColumns, nil
+	}
+	selected := make(map[string]bool)
+	for _, c := range strings.Split(value, ",") {
+		c = strings.TrimSpace(c)
+		if c == "" {
+			continue
+		}
+		known := false
+		for _, oc := range profileOptionalColumns {
+			if c == oc {
+				known = true
+			}
+		}
+		if !known {
+			return nil, errors.New("Unknown profile column: " + c)
+		}
+		selected[c] = true
+	}
+	columns := make([]string, 0)
+	for _, oc := range profileOptionalColumns {
+		if selected[oc] {
+			columns = append(columns, oc)
+		}
+	}
+	return columns, nil
+}
+
 func (t *ReportMemberProfile) Report(context *integration.ReportContext) error {
-	members, err := crawler.AllTeamMembers(context)
+	columns := t.Columns
+	members, err := context.Members.Members()
 	if err != nil {
 		return err
 	}
 
-	context.ReportOutput.Headers(t.createHeader())
+	extras := &memberProfileExtras{}
+	for _, c := range columns {
+		switch {
+		case c == "groups" && extras.groupNames == nil:
+			groups, err := crawler.AllGroups(context)
+			if err != nil {
+				return err
+			}
+			extras.groupNames = make(map[string]string)
+			for _, g := range groups {
+				extras.groupNames[g.GroupId] = g.GroupName
+			}
+
+		case (c == "secondary-emails" || c == "member-folder-id") && extras.extras == nil:
+			extras.extras, err = crawler.AllTeamMemberExtras(context)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	context.ReportOutput.Headers(append(t.createHeader(), columns...))
 
 	for _, m := range members {
-		context.ReportOutput.Row(t.createRow(m))
+		row := t.createRow(m)
+		for _, c := range columns {
+			row = append(row, t.optionalColumn(c, m, extras))
+		}
+		context.ReportOutput.Row(row)
 	}
 
 	return nil
@@ -63,3 +169,52 @@ func (t *ReportMemberProfile) createRow(member *team.TeamMemberInfo) []string {
 		member.Profile.Status.Tag,
 	}
 }
+
+func (t *ReportMemberProfile) optionalColumn(column string, member *team.TeamMemberInfo, extras *memberProfileExtras) string {
+	profile := member.Profile
+	extra := extras.extras[profile.TeamMemberId]
+
+	switch column {
+	case "given-name":
+		if profile.Name != nil {
+			return profile.Name.GivenName
+		}
+	case "surname":
+		if profile.Name != nil {
+			return profile.Name.Surname
+		}
+	case "display-name":
+		if profile.Name != nil {
+			return profile.Name.DisplayName
+		}
+	case "joined-on":
+		if !profile.JoinedOn.IsZero() {
+			return profile.JoinedOn.String()
+		}
+	case "groups":
+		groups := make([]string, 0, len(profile.Groups))
+		for _, gid := range profile.Groups {
+			if name, ok := extras.groupNames[gid]; ok {
+				groups = append(groups, name)
+			} else {
+				groups = append(groups, gid)
+			}
+		}
+		return strings.Join(groups, ";")
+	case "persistent-id":
+		return profile.PersistentId
+	case "secondary-emails":
+		if extra != nil {
+			emails := make([]string, 0, len(extra.SecondaryEmails))
+			for _, e := range extra.SecondaryEmails {
+				emails = append(emails, e.Email)
+			}
+			return strings.Join(emails, ";")
+		}
+	case "member-folder-id":
+		if extra != nil {
+			return extra.MemberFolderId
+		}
+	}
+	return ""
+}
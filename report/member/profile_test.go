@@ -0,0 +1,33 @@
+package member
+
+import (
+	"flag"
+	"io/ioutil"
+	"strings"
+	"testing"
+)
+
+func TestProfileColumnsOption(t *testing.T) {
+	r := &ReportMemberProfile{}
+	f := flag.NewFlagSet("test", flag.ContinueOnError)
+	f.SetOutput(ioutil.Discard)
+	r.DefineOptions(f)
+
+	if err := f.Parse([]string{"-profile-columns", "groups, given-name"}); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(r.Columns, ",") != "given-name,groups" {
+		t.Errorf("Unexpected columns: %v", r.Columns)
+	}
+
+	if err := f.Parse([]string{"-profile-columns", "all"}); err != nil {
+		t.Fatal(err)
+	}
+	if len(r.Columns) != len(profileOptionalColumns) {
+		t.Errorf("Unexpected columns: %v", r.Columns)
+	}
+
+	if err := f.Parse([]string{"-profile-columns", "groups,unknown"}); err == nil {
+		t.Error("Unknown column should be an error")
+	}
+}
@@ -1,6 +1,9 @@
 package member
 
 import (
+	"encoding/csv"
+	"flag"
+	"fmt"
 	"github.com/cihub/seelog"
 	"github.com/dropbox/dropbox-sdk-go-unofficial"
 	"github.com/dropbox/dropbox-sdk-go-unofficial/team"
@@ -8,10 +11,20 @@ import (
 	"github.com/watermint/dreport/auth"
 	"github.com/watermint/dreport/crawler"
 	"github.com/watermint/dreport/integration"
+	"os"
 	"strconv"
 )
 
 type ReportQuotaUsage struct {
+	Summary bool
+}
+
+// Team total of usage and allocation. Team allocation is shared by members, thus
+// recorded once, and individual allocations are summed.
+type quotaSummary struct {
+	Used                uint64 `json:"used"`
+	TeamAllocated       uint64 `json:"team_allocated"`
+	IndividualAllocated uint64 `json:"individual_allocated"`
 }
 
 func (t *ReportQuotaUsage) ReportName() string {
@@ -29,14 +42,40 @@ func (t *ReportQuotaUsage) RequiredPermissions() []string {
 	}
 }
 
+func (t *ReportQuotaUsage) Resumable() bool {
+	return true
+}
+
+func (t *ReportQuotaUsage) DefineOptions(f *flag.FlagSet) {
+	f.BoolVar(&t.Summary, "quota-summary", false, "Write team total usage and allocation of TeamMemberQuota into the summary file (<output>.summary.csv)")
+}
+
 func (t *ReportQuotaUsage) Report(context *integration.ReportContext) error {
-	members, err := crawler.AllTeamMembers(context)
+	members, err := context.Members.Members()
 	if err != nil {
 		return err
 	}
-	context.ReportOutput.Headers(t.createHeader())
 
+	teamMemberIds := make([]string, 0, len(members))
 	for _, m := range members {
+		teamMemberIds = append(teamMemberIds, m.Profile.TeamMemberId)
+	}
+	quotas, err := crawler.CustomQuotas(context, teamMemberIds)
+	if err != nil {
+		seelog.Warnf("Unable to load custom quota of members. Skip quota-cap: %s", err)
+		quotas = make(map[string]uint32)
+	}
+
+	summary := &quotaSummary{}
+	context.Checkpoint.Get("quota/summary", summary)
+
+	context.ReportOutput.Headers(t.createHeader())
+
+	loadUsage := func(i int) (interface{}, error) {
+		m := members[i]
+		if context.Checkpoint.IsDone("quota/" + m.Profile.TeamMemberId) {
+			return nil, nil
+		}
 		memberClient := dropbox.Client(context.TeamFileToken, dropbox.Options{
 			AsMemberId: m.Profile.TeamMemberId,
 		})
@@ -44,29 +83,169 @@ func (t *ReportQuotaUsage) Report(context *integration.ReportContext) error {
 		usage, err := memberClient.GetSpaceUsage()
 		if err != nil {
 			seelog.Errorf("Unable to load quota for member: '%s'", m.Profile.AccountId)
+			return nil, err
+		}
+		return usage, nil
+	}
+	writeUsage := func(i int, usage interface{}, err error) error {
+		if err != nil {
+			return context.HandleError(members[i].Profile.TeamMemberId, err)
+		}
+		if usage == nil {
+			return nil
+		}
+		u := usage.(*users.SpaceUsage)
+		quotaCap, hasCap := quotas[members[i].Profile.TeamMemberId]
+		if err := context.ReportOutput.Row(t.createRow(members[i], u, quotaCap, hasCap)); err != nil {
 			return err
 		}
-
-		context.ReportOutput.Row(t.createRow(m, usage))
+		t.summarise(summary, u)
+		context.Checkpoint.Set("quota/summary", summary)
+		context.Checkpoint.Done("quota/" + members[i].Profile.TeamMemberId)
+		return context.Checkpoint.Commit()
 	}
 
+	if err := crawler.ForEachOrdered(len(members), context.Concurrency, loadUsage, writeUsage); err != nil {
+		return err
+	}
+	if t.Summary {
+		return t.writeSummary(context.SummaryFile, summary)
+	}
 	return nil
 }
 
+func (t *ReportQuotaUsage) summarise(summary *quotaSummary, usage *users.SpaceUsage) {
+	summary.Used += usage.Used
+	if usage.Allocation == nil {
+		return
+	}
+	switch usage.Allocation.Tag {
+	case "team":
+		summary.TeamAllocated = usage.Allocation.Team.Allocated
+	case "individual":
+		summary.IndividualAllocated += usage.Allocation.Individual.Allocated
+	}
+}
+
 func (t *ReportQuotaUsage) createHeader() []string {
 	return []string{
 		"account-id",
 		"team-member-id",
 		"email",
 		"usage",
+		"usage-human",
+		"allocation-type",
+		"allocated",
+		"allocated-human",
+		"quota-cap",
+		"quota-cap-human",
+		"percent-used",
 	}
 }
 
-func (t *ReportQuotaUsage) createRow(member *team.TeamMemberInfo, usage *users.SpaceUsage) []string {
+func (t *ReportQuotaUsage) createRow(member *team.TeamMemberInfo, usage *users.SpaceUsage, quotaCapGb uint32, hasCap bool) []string {
+	allocationType := ""
+	var allocated uint64
+	if usage.Allocation != nil {
+		allocationType = usage.Allocation.Tag
+		switch allocationType {
+		case "team":
+			allocated = usage.Allocation.Team.Allocated
+		case "individual":
+			allocated = usage.Allocation.Individual.Allocated
+		}
+	}
+
+	// Team allocation is shared by members, thus percentage against the team
+	// allocation is not the usage of the member.
+	quotaCap := ""
+	quotaCapHuman := ""
+	var limit uint64
+	if allocationType == "individual" {
+		limit = allocated
+	}
+	if hasCap {
+		capBytes := uint64(quotaCapGb) * 1024 * 1024 * 1024
+		quotaCap = strconv.FormatUint(capBytes, 10)
+		quotaCapHuman = humanSize(capBytes)
+		limit = capBytes
+	}
+
 	return []string{
 		member.Profile.AccountId,
 		member.Profile.TeamMemberId,
 		member.Profile.Email,
 		strconv.FormatUint(usage.Used, 10),
+		humanSize(usage.Used),
+		allocationType,
+		strconv.FormatUint(allocated, 10),
+		humanSize(allocated),
+		quotaCap,
+		quotaCapHuman,
+		percentUsed(usage.Used, limit),
+	}
+}
+
+func (t *ReportQuotaUsage) createSummaryHeader() []string {
+	return []string{
+		"usage",
+		"usage-human",
+		"allocated",
+		"allocated-human",
+		"percent-used",
+	}
+}
+
+func (t *ReportQuotaUsage) createSummaryRow(summary *quotaSummary) []string {
+	allocated := summary.TeamAllocated + summary.IndividualAllocated
+	return []string{
+		strconv.FormatUint(summary.Used, 10),
+		humanSize(summary.Used),
+		strconv.FormatUint(allocated, 10),
+		humanSize(allocated),
+		percentUsed(summary.Used, allocated),
+	}
+}
+
+// Write team total into the summary file.
+func (t *ReportQuotaUsage) writeSummary(path string, summary *quotaSummary) error {
+	seelog.Infof("Writing team total usage: '%s'", path)
+	out, err := os.Create(path)
+	if err != nil {
+		seelog.Errorf("Unable to create summary file: '%s'", path)
+		return err
+	}
+	defer out.Close()
+
+	w := csv.NewWriter(out)
+	w.Write(t.createSummaryHeader())
+	w.Write(t.createSummaryRow(summary))
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return err
+	}
+	return out.Close()
+}
+
+// Percentage of used against the limit. Empty if no limit.
+func percentUsed(used, limit uint64) string {
+	if limit == 0 {
+		return ""
+	}
+	return fmt.Sprintf("%.1f", float64(used)*100/float64(limit))
+}
+
+// Size in binary units, e.g. '1.5 GB'.
+func humanSize(size uint64) string {
+	units := []string{"B", "KB", "MB", "GB", "TB", "PB"}
+	s := float64(size)
+	u := 0
+	for s >= 1024 && u < len(units)-1 {
+		s /= 1024
+		u++
+	}
+	if u == 0 {
+		return fmt.Sprintf("%d %s", size, units[u])
 	}
+	return fmt.Sprintf("%.1f %s", s, units[u])
 }
@@ -0,0 +1,92 @@
+package member
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/dropbox/dropbox-sdk-go-unofficial/team"
+	"github.com/dropbox/dropbox-sdk-go-unofficial/users"
+)
+
+func TestHumanSize(t *testing.T) {
+	sizes := map[uint64]string{
+		0:                      "0 B",
+		1023:                   "1023 B",
+		1024:                   "1.0 KB",
+		1536:                   "1.5 KB",
+		5 * 1024 * 1024 * 1024: "5.0 GB",
+		1 << 60:                "1024.0 PB",
+	}
+	for size, expected := range sizes {
+		if h := humanSize(size); h != expected {
+			t.Errorf("Unexpected size of %d: %s", size, h)
+		}
+	}
+}
+
+func TestPercentUsed(t *testing.T) {
+	if p := percentUsed(1, 3); p != "33.3" {
+		t.Errorf("Unexpected percentage: %s", p)
+	}
+	if p := percentUsed(1, 0); p != "" {
+		t.Errorf("Percentage without limit should be empty: %s", p)
+	}
+}
+
+func TestQuotaPercentUsed(t *testing.T) {
+	r := &ReportQuotaUsage{}
+	member := &team.TeamMemberInfo{Profile: &team.TeamMemberProfile{}}
+	percentColumn := len(r.createHeader()) - 1
+
+	teamUsage := &users.SpaceUsage{
+		Used:       1024,
+		Allocation: &users.SpaceAllocation{Team: &users.TeamSpaceAllocation{Allocated: 4096}},
+	}
+	teamUsage.Allocation.Tag = "team"
+	if row := r.createRow(member, teamUsage, 0, false); row[percentColumn] != "" {
+		t.Errorf("Percentage of team allocation should be empty: %v", row)
+	}
+	if row := r.createRow(member, teamUsage, 1, true); row[percentColumn] != "0.0" {
+		t.Errorf("Percentage should be against the quota cap: %v", row)
+	}
+
+	individualUsage := &users.SpaceUsage{
+		Used:       1024,
+		Allocation: &users.SpaceAllocation{Individual: &users.IndividualSpaceAllocation{Allocated: 4096}},
+	}
+	individualUsage.Allocation.Tag = "individual"
+	if row := r.createRow(member, individualUsage, 0, false); row[percentColumn] != "25.0" {
+		t.Errorf("Percentage should be against the individual allocation: %v", row)
+	}
+}
+
+func TestQuotaSummary(t *testing.T) {
+	dir, err := ioutil.TempDir("", "dreport")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	r := &ReportQuotaUsage{}
+	summary := &quotaSummary{}
+	for i := 0; i < 2; i++ {
+		usage := &users.SpaceUsage{
+			Used:       1024,
+			Allocation: &users.SpaceAllocation{Team: &users.TeamSpaceAllocation{Allocated: 8192}},
+		}
+		usage.Allocation.Tag = "team"
+		r.summarise(summary, usage)
+	}
+
+	path := filepath.Join(dir, "quota.csv.summary.csv")
+	if err := r.writeSummary(path, summary); err != nil {
+		t.Fatal(err)
+	}
+	content, _ := ioutil.ReadFile(path)
+	expected := "usage,usage-human,allocated,allocated-human,percent-used\n2048,2.0 KB,8192,8.0 KB,25.0\n"
+	if string(content) != expected {
+		t.Errorf("Unexpected summary: %q", content)
+	}
+}
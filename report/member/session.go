@@ -2,7 +2,6 @@ package member
 
 import (
 	"github.com/cihub/seelog"
-	"github.com/dropbox/dropbox-sdk-go-unofficial"
 	"github.com/dropbox/dropbox-sdk-go-unofficial/team"
 	"github.com/watermint/dreport/auth"
 	"github.com/watermint/dreport/crawler"
@@ -28,65 +27,40 @@ func (t *ReportMemberSessions) RequiredPermissions() []string {
 	}
 }
 
+func (t *ReportMemberSessions) Resumable() bool {
+	return true
+}
+
 func (t *ReportMemberSessions) Report(context *integration.ReportContext) error {
-	members, err := crawler.AllTeamMembers(context)
-	if err != nil {
-		seelog.Errorf("Unable to load member list", err)
+	if _, err := context.Members.Members(); err != nil {
+		seelog.Errorf("Unable to load member list: %v", err)
 		return err
 	}
-	membersMap := make(map[string]*team.TeamMemberInfo)
-	for _, m := range members {
-		membersMap[m.Profile.TeamMemberId] = m
-	}
-
-	fileClient := dropbox.Client(context.TeamFileToken, dropbox.Options{})
 
 	context.ReportOutput.Headers(t.createHeader())
 
-	seelog.Info("Loading sessions")
-	query := team.NewListMembersDevicesArg()
-	query.IncludeDesktopClients = true
-	query.IncludeMobileClients = true
-	query.IncludeWebSessions = true
-	sessions, err := fileClient.DevicesListMembersDevices(query)
-	if err != nil {
-		seelog.Error("Unable to load members sessions", err)
-		return err
-	}
-	for {
-		for _, d := range sessions.Devices {
-			member, found := membersMap[d.TeamMemberId]
-			if !found {
-				seelog.Errorf("Member profile not found for Team Member Id: %s", d.TeamMemberId)
-				continue
-			}
-			for _, s := range d.DesktopClients {
-				context.ReportOutput.Row(t.createDesktopSession(member, s))
-			}
-			for _, s := range d.MobileClients {
-				context.ReportOutput.Row(t.createMobileSession(member, s))
-			}
-			for _, s := range d.WebSessions {
-				context.ReportOutput.Row(t.createWebSession(member, s))
-			}
-		}
-		if !sessions.HasMore {
-			seelog.Info("Finished")
+	var cursor string
+	context.Checkpoint.Get("sessions", &cursor)
+	return crawler.ResumeMemberDevices(context, cursor, func(d *team.MemberDevices) error {
+		member, found := context.Members.ByTeamMemberId(d.TeamMemberId)
+		if !found {
+			seelog.Errorf("Member profile not found for Team Member Id: %s", d.TeamMemberId)
 			return nil
 		}
-
-		seelog.Info("Loading more sessions..")
-		query = team.NewListMembersDevicesArg()
-		query.Cursor = sessions.Cursor
-		query.IncludeDesktopClients = true
-		query.IncludeMobileClients = true
-		query.IncludeWebSessions = true
-
-		sessions, err = fileClient.DevicesListMembersDevices(query)
-		if err != nil {
-			seelog.Error("Unable to load member (contiue)", err)
+		for _, s := range d.DesktopClients {
+			context.ReportOutput.Row(t.createDesktopSession(member, s))
 		}
-	}
+		for _, s := range d.MobileClients {
+			context.ReportOutput.Row(t.createMobileSession(member, s))
+		}
+		for _, s := range d.WebSessions {
+			context.ReportOutput.Row(t.createWebSession(member, s))
+		}
+		return nil
+	}, func(cursor string) error {
+		context.Checkpoint.Set("sessions", cursor)
+		return context.Checkpoint.Commit()
+	})
 }
 
 func (t *ReportMemberSessions) createHeader() []string {
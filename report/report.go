@@ -1,6 +1,10 @@
 package report
 
-import "github.com/watermint/dreport/integration"
+import (
+	"flag"
+
+	"github.com/watermint/dreport/integration"
+)
 
 type Report interface {
 	ReportName() string
@@ -8,3 +12,20 @@ type Report interface {
 	RequiredPermissions() []string
 	Report(context *integration.ReportContext) error
 }
+
+// ReportOptions is implemented by reports which accept report specific options.
+type ReportOptions interface {
+	DefineOptions(f *flag.FlagSet)
+}
+
+// ReportOptionalPermissions is implemented by reports which use additional
+// permissions if the token is available without the authorisation dialogue.
+type ReportOptionalPermissions interface {
+	OptionalPermissions() []string
+}
+
+// ReportResumable is implemented by reports which record progress into the
+// checkpoint, thus the report can continue from the interrupted run.
+type ReportResumable interface {
+	Resumable() bool
+}
@@ -0,0 +1,102 @@
+package sharing
+
+import (
+	"github.com/cihub/seelog"
+	"github.com/dropbox/dropbox-sdk-go-unofficial"
+	"github.com/dropbox/dropbox-sdk-go-unofficial/users"
+	"github.com/watermint/dreport/integration"
+	"sync"
+)
+
+// Resolves email and display name of accounts. Team members are resolved by the member
+// directory, and accounts outside of the team are resolved by the account lookup API.
+type accountResolver struct {
+	rc       *integration.ReportContext
+	mutex    sync.Mutex
+	accounts map[string]*accountLookup
+}
+
+// Lookup of the account. Concurrent lookups of the same account wait for done.
+type accountLookup struct {
+	done    chan struct{}
+	account *users.BasicAccount
+}
+
+func newAccountResolver(rc *integration.ReportContext) *accountResolver {
+	return &accountResolver{
+		rc:       rc,
+		accounts: make(map[string]*accountLookup),
+	}
+}
+
+// Resolve returns email and display name of the account. Returns empty strings if the
+// account does not exist, or the lookup failed.
+func (r *accountResolver) Resolve(asMemberId, teamMemberId, accountId string) (email, displayName string) {
+	if teamMemberId != "" {
+		if m, found := r.rc.Members.ByTeamMemberId(teamMemberId); found {
+			return r.memberNames(m.Profile.Email, m.Profile.Name)
+		}
+	}
+	if accountId == "" {
+		return "", ""
+	}
+	if m, found := r.rc.Members.ByAccountId(accountId); found {
+		return r.memberNames(m.Profile.Email, m.Profile.Name)
+	}
+
+	account := r.lookup(asMemberId, accountId)
+	if account == nil {
+		return "", ""
+	}
+	return r.memberNames(account.Email, account.Name)
+}
+
+func (r *accountResolver) memberNames(email string, name *users.Name) (string, string) {
+	if name == nil {
+		return email, ""
+	}
+	return email, name.DisplayName
+}
+
+func isNoAccount(err error) bool {
+	switch e := err.(type) {
+	case dropbox.GetAccountAPIError:
+		return e.EndpointError != nil && e.EndpointError.Tag == "no_account"
+	case *dropbox.GetAccountAPIError:
+		return e.EndpointError != nil && e.EndpointError.Tag == "no_account"
+	default:
+		return false
+	}
+}
+
+// Look up the account. Returns nil if the account does not exist, or the lookup failed.
+func (r *accountResolver) lookup(asMemberId, accountId string) *users.BasicAccount {
+	r.mutex.Lock()
+	if l, found := r.accounts[accountId]; found {
+		r.mutex.Unlock()
+		<-l.done
+		return l.account
+	}
+	l := &accountLookup{done: make(chan struct{})}
+	r.accounts[accountId] = l
+	r.mutex.Unlock()
+
+	client := dropbox.Client(r.rc.TeamFileToken, dropbox.Options{
+		AsMemberId: asMemberId,
+	})
+	account, err := client.GetAccount(users.NewGetAccountArg(accountId))
+	switch {
+	case err == nil:
+		l.account = account
+	case isNoAccount(err):
+		seelog.Warnf("Account not found: '%s'", accountId)
+	default:
+		seelog.Warnf("Unable to look up account '%s', leave email and display name empty: %s", accountId, err)
+		// Forget the failure, to look up again on the next call
+		r.mutex.Lock()
+		delete(r.accounts, accountId)
+		r.mutex.Unlock()
+	}
+	close(l.done)
+	return l.account
+}
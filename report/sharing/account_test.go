@@ -0,0 +1,26 @@
+package sharing
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/dropbox/dropbox-sdk-go-unofficial"
+	"github.com/dropbox/dropbox-sdk-go-unofficial/users"
+)
+
+func TestIsNoAccount(t *testing.T) {
+	noAccount := dropbox.GetAccountAPIError{EndpointError: &users.GetAccountError{}}
+	noAccount.EndpointError.Tag = "no_account"
+	if !isNoAccount(noAccount) || !isNoAccount(&noAccount) {
+		t.Error("Should be no_account")
+	}
+
+	other := dropbox.GetAccountAPIError{EndpointError: &users.GetAccountError{}}
+	other.EndpointError.Tag = "other"
+	if isNoAccount(other) {
+		t.Error("Should not be no_account")
+	}
+	if isNoAccount(errors.New("no_account")) {
+		t.Error("Untyped error should not be no_account")
+	}
+}
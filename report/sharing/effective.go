@@ -0,0 +1,139 @@
+package sharing
+
+import (
+	"github.com/dropbox/dropbox-sdk-go-unofficial/sharing"
+	"github.com/dropbox/dropbox-sdk-go-unofficial/team"
+	"github.com/watermint/dreport/crawler"
+	"github.com/watermint/dreport/integration"
+	"strconv"
+	"sync"
+)
+
+// Rank of access levels. Higher access level wins if the user has several grants.
+var accessLevelRank = map[string]int{
+	"viewer_no_comment": 1,
+	"viewer":            2,
+	"editor":            3,
+	"owner":             4,
+}
+
+// Members of groups, shared across shared folders.
+type groupMembersCache struct {
+	rc      *integration.ReportContext
+	mutex   sync.Mutex
+	members map[string][]*team.GroupMemberInfo
+}
+
+func newGroupMembersCache(rc *integration.ReportContext) *groupMembersCache {
+	return &groupMembersCache{
+		rc:      rc,
+		members: make(map[string][]*team.GroupMemberInfo),
+	}
+}
+
+func (c *groupMembersCache) GroupMembers(groupId string) ([]*team.GroupMemberInfo, error) {
+	c.mutex.Lock()
+	members, ok := c.members[groupId]
+	c.mutex.Unlock()
+	if ok {
+		return members, nil
+	}
+
+	members, err := crawler.AllGroupMembers(c.rc, groupId)
+	if err != nil {
+		return nil, err
+	}
+
+	c.mutex.Lock()
+	c.members[groupId] = members
+	c.mutex.Unlock()
+	return members, nil
+}
+
+// Effective access of the user to the shared folder, and the grant of the access.
+type effectiveAccess struct {
+	accessLevel  string
+	accountId    string
+	teamMemberId string
+	email        string
+	sameTeam     string
+	grantedVia   string
+	group        *sharing.GroupInfo
+}
+
+// Resolve grants of the shared folder into effective access per user.
+// Users are identified by account id, or team member id and email for invited users
+// without account.
+func resolveEffectiveAccess(fm *sharedFolderMembers) []*effectiveAccess {
+	accesses := make([]*effectiveAccess, 0)
+	index := make(map[string]int)
+
+	grant := func(key string, a *effectiveAccess) {
+		i, found := index[key]
+		if !found {
+			index[key] = len(accesses)
+			accesses = append(accesses, a)
+			return
+		}
+		if accessLevelRank[a.accessLevel] > accessLevelRank[accesses[i].accessLevel] {
+			accesses[i] = a
+		}
+	}
+
+	for _, u := range fm.users {
+		grant(u.User.AccountId, &effectiveAccess{
+			accessLevel:  u.AccessType.Tag,
+			accountId:    u.User.AccountId,
+			teamMemberId: u.User.TeamMemberId,
+			sameTeam:     strconv.FormatBool(u.User.SameTeam),
+			grantedVia:   "user",
+		})
+	}
+	for _, g := range fm.groups {
+		members, expanded := fm.groupMembers[g.Group.GroupId]
+		if !expanded {
+			// Group outside of the team. Keep the grant as is.
+			grant("group:"+g.Group.GroupId, &effectiveAccess{
+				accessLevel: g.AccessType.Tag,
+				grantedVia:  "group",
+				group:       g.Group,
+			})
+			continue
+		}
+		for _, m := range members {
+			key := m.Profile.AccountId
+			switch {
+			case key != "":
+			case m.Profile.TeamMemberId != "":
+				key = "member:" + m.Profile.TeamMemberId
+			default:
+				key = "invitee:" + m.Profile.Email
+			}
+			grant(key, &effectiveAccess{
+				accessLevel:  g.AccessType.Tag,
+				accountId:    m.Profile.AccountId,
+				teamMemberId: m.Profile.TeamMemberId,
+				email:        m.Profile.Email,
+				sameTeam:     strconv.FormatBool(true),
+				grantedVia:   "group",
+				group:        g.Group,
+			})
+		}
+	}
+	for _, i := range fm.invitees {
+		a := &effectiveAccess{
+			accessLevel: i.AccessType.Tag,
+			email:       i.Invitee.Email,
+			grantedVia:  "invitee",
+		}
+		key := "invitee:" + i.Invitee.Email
+		if i.User != nil {
+			a.accountId = i.User.AccountId
+			a.teamMemberId = i.User.TeamMemberId
+			a.sameTeam = strconv.FormatBool(i.User.SameTeam)
+			key = i.User.AccountId
+		}
+		grant(key, a)
+	}
+	return accesses
+}
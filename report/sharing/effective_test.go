@@ -0,0 +1,107 @@
+package sharing
+
+import (
+	"testing"
+
+	"github.com/dropbox/dropbox-sdk-go-unofficial/sharing"
+	"github.com/dropbox/dropbox-sdk-go-unofficial/team"
+)
+
+func testAccessLevel(tag string) sharing.MembershipInfo {
+	level := &sharing.AccessLevel{}
+	level.Tag = tag
+	return sharing.MembershipInfo{AccessType: level}
+}
+
+func testGroupMember(accountId string) *team.GroupMemberInfo {
+	return &team.GroupMemberInfo{
+		Profile: &team.MemberProfile{
+			TeamMemberId: "dbmid:" + accountId,
+			AccountId:    accountId,
+			Email:        accountId + "@example.com",
+		},
+	}
+}
+
+func TestResolveEffectiveAccess(t *testing.T) {
+	fm := &sharedFolderMembers{
+		users: []*sharing.UserMembershipInfo{
+			{MembershipInfo: testAccessLevel("owner"), User: &sharing.UserInfo{AccountId: "a", SameTeam: true}},
+			{MembershipInfo: testAccessLevel("viewer"), User: &sharing.UserInfo{AccountId: "b", SameTeam: true}},
+		},
+		groups: []*sharing.GroupMembershipInfo{
+			// Lower access than the direct grant
+			{MembershipInfo: testAccessLevel("editor"), Group: &sharing.GroupInfo{GroupId: "g:1"}},
+			// Higher access than the direct grant
+			{MembershipInfo: testAccessLevel("editor"), Group: &sharing.GroupInfo{GroupId: "g:2"}},
+			// Group outside of the team
+			{MembershipInfo: testAccessLevel("viewer"), Group: &sharing.GroupInfo{GroupId: "g:3"}},
+		},
+		invitees: []*sharing.InviteeMembershipInfo{
+			{MembershipInfo: testAccessLevel("viewer_no_comment"), Invitee: &sharing.InviteeInfo{Email: "c@example.com"}, User: &sharing.UserInfo{AccountId: "c"}},
+			{MembershipInfo: testAccessLevel("viewer"), Invitee: &sharing.InviteeInfo{Email: "d@example.com"}},
+		},
+		groupMembers: map[string][]*team.GroupMemberInfo{
+			"g:1": {testGroupMember("a")},
+			"g:2": {testGroupMember("b"), testGroupMember("c")},
+		},
+	}
+
+	accesses := resolveEffectiveAccess(fm)
+	type expectation struct {
+		accessLevel string
+		grantedVia  string
+	}
+	expected := map[string]expectation{
+		"a":                     {"owner", "user"},
+		"b":                     {"editor", "group"},
+		"c":                     {"editor", "group"},
+		"group:g:3":             {"viewer", "group"},
+		"invitee:d@example.com": {"viewer", "invitee"},
+	}
+	if len(accesses) != len(expected) {
+		t.Fatalf("Unexpected number of accesses: %d", len(accesses))
+	}
+	for _, a := range accesses {
+		key := a.accountId
+		switch {
+		case a.group != nil && a.accountId == "":
+			key = "group:" + a.group.GroupId
+		case a.accountId == "":
+			key = "invitee:" + a.email
+		}
+		e, found := expected[key]
+		if !found {
+			t.Errorf("Unexpected access: %v", a)
+			continue
+		}
+		if a.accessLevel != e.accessLevel || a.grantedVia != e.grantedVia {
+			t.Errorf("Unexpected access of '%s': %s via %s", key, a.accessLevel, a.grantedVia)
+		}
+	}
+}
+
+func TestResolveEffectiveAccessInvitedGroupMembers(t *testing.T) {
+	// Invited members of the group do not have account id yet
+	invited := func(id string) *team.GroupMemberInfo {
+		m := testGroupMember(id)
+		m.Profile.AccountId = ""
+		return m
+	}
+	fm := &sharedFolderMembers{
+		groups: []*sharing.GroupMembershipInfo{
+			{MembershipInfo: testAccessLevel("editor"), Group: &sharing.GroupInfo{GroupId: "g:1"}},
+		},
+		groupMembers: map[string][]*team.GroupMemberInfo{
+			"g:1": {invited("a"), invited("b")},
+		},
+	}
+
+	accesses := resolveEffectiveAccess(fm)
+	if len(accesses) != 2 {
+		t.Fatalf("Unexpected number of accesses: %d", len(accesses))
+	}
+	if accesses[0].teamMemberId != "dbmid:a" || accesses[1].teamMemberId != "dbmid:b" {
+		t.Errorf("Unexpected accesses: %v %v", accesses[0], accesses[1])
+	}
+}
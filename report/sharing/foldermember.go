@@ -7,10 +7,29 @@ import (
 	"github.com/dropbox/dropbox-sdk-go-unofficial/sharing"
 	"github.com/dropbox/dropbox-sdk-go-unofficial"
 	"github.com/cihub/seelog"
+	"github.com/dropbox/dropbox-sdk-go-unofficial/team"
+	"flag"
+	"sort"
 	"strconv"
 )
 
 type ReportSharedFolderMembers struct {
+	EffectiveAccess bool
+}
+
+// Shared folders discovered, and the member to access each shared folder.
+type discoveredFolders struct {
+	Folders  map[string]*sharing.SharedFolderMetadata `json:"folders"`
+	AsMember map[string]string                        `json:"as_member"`
+}
+
+type sharedFolderMembers struct {
+	groups   []*sharing.GroupMembershipInfo
+	users    []*sharing.UserMembershipInfo
+	invitees []*sharing.InviteeMembershipInfo
+
+	// Members of groups of the team, loaded only for effective access mode.
+	groupMembers map[string][]*team.GroupMemberInfo
 }
 
 func (t *ReportSharedFolderMembers) ReportName() string {
@@ -28,8 +47,16 @@ func (t *ReportSharedFolderMembers) RequiredPermissions() []string {
 	}
 }
 
+func (t *ReportSharedFolderMembers) Resumable() bool {
+	return true
+}
+
+func (t *ReportSharedFolderMembers) DefineOptions(f *flag.FlagSet) {
+	f.BoolVar(&t.EffectiveAccess, "sharing-effective-access", false, "Expand groups of SharedFolderMembers into one row per user, with the highest access level of the user")
+}
+
 func (t *ReportSharedFolderMembers) Report(rc *integration.ReportContext) error {
-	members, err := crawler.AllTeamMembers(rc)
+	members, err := rc.Members.Members()
 	if err != nil {
 		return err
 	}
@@ -37,51 +64,149 @@ func (t *ReportSharedFolderMembers) Report(rc *integration.ReportContext) error
 	rc.ReportOutput.Headers(t.createHeader())
 
 	// Load all shared folders
-	sharedFolders := make(map[string]*sharing.SharedFolderMetadata)
-	sharedFolderAsMember := make(map[string]string)
-	for _, m := range members {
+	discovered := &discoveredFolders{
+		Folders:  make(map[string]*sharing.SharedFolderMetadata),
+		AsMember: make(map[string]string),
+	}
+	rc.Checkpoint.Get("shared-folders", discovered)
+	sharedFolders := discovered.Folders
+	sharedFolderAsMember := discovered.AsMember
+	loadFolders := func(i int) (interface{}, error) {
+		m := members[i]
+		key := "shared-folders/" + m.Profile.TeamMemberId
+		if rc.Checkpoint.IsDone(key) {
+			return nil, nil
+		}
 		client := dropbox.Client(rc.TeamFileToken, dropbox.Options{
 			AsMemberId: m.Profile.TeamMemberId,
 		})
-		folders, err := crawler.AllSharedFolders(client)
+		folders, err := crawler.ResumeSharedFolders(client, rc.Checkpoint, key)
 		if err != nil {
 			seelog.Errorf("Unable to load shared folders for member (%s)", m.Profile.TeamMemberId)
-			return err
+			return nil, err
 		}
-		for _, f := range folders {
+		return folders, nil
+	}
+	mergeFolders := func(i int, folders interface{}, err error) error {
+		if err != nil {
+			return rc.HandleError(members[i].Profile.TeamMemberId, err)
+		}
+		if folders == nil {
+			return nil
+		}
+		for _, f := range folders.([]*sharing.SharedFolderMetadata) {
 			sharedFolders[f.SharedFolderId] = f
-			sharedFolderAsMember[f.SharedFolderId] = m.Profile.TeamMemberId
+			sharedFolderAsMember[f.SharedFolderId] = members[i].Profile.TeamMemberId
 		}
+		rc.Checkpoint.Set("shared-folders", discovered)
+		rc.Checkpoint.Done("shared-folders/" + members[i].Profile.TeamMemberId)
+		return rc.Checkpoint.Commit()
+	}
+	if err := crawler.ForEachOrdered(len(members), rc.Concurrency, loadFolders, mergeFolders); err != nil {
+		return err
 	}
 
+	// Sort shared folders to make the output order deterministic
+	sharedFolderIds := make([]string, 0, len(sharedFolders))
+	for sfid := range sharedFolders {
+		sharedFolderIds = append(sharedFolderIds, sfid)
+	}
+	sort.Strings(sharedFolderIds)
+
 	// Load shared folder members
-	for sfid, sf := range sharedFolders {
-		asMember, found := sharedFolderAsMember[sfid]
-		if !found {
-			seelog.Warnf("Unexpected condition. Could not determine 'AsMemberId' for shared folder '%s'", sfid)
-			continue
+	groupMembers := newGroupMembersCache(rc)
+	accounts := newAccountResolver(rc)
+	loadFolderMembers := func(i int) (interface{}, error) {
+		sfid := sharedFolderIds[i]
+		if rc.Checkpoint.IsDone("folder-members/" + sfid) {
+			return nil, nil
 		}
 		client := dropbox.Client(rc.TeamFileToken, dropbox.Options{
-			AsMemberId: asMember,
+			AsMemberId: sharedFolderAsMember[sfid],
 		})
 		groups, users, invitees, err := crawler.AllSharedFolderMembers(client, sfid)
 		if err != nil {
-			seelog.Warnf("Unable to load shared folder member information for shared folder '%s'", sfid)
-			continue
+			return nil, err
 		}
-
-		for _, g := range groups {
-			rc.ReportOutput.Row(t.createGroupRow(sf, g))
+		fm := &sharedFolderMembers{
+			groups:       groups,
+			users:        users,
+			invitees:     invitees,
+			groupMembers: make(map[string][]*team.GroupMemberInfo),
 		}
+		// Resolve accounts in advance, to look up external accounts concurrently
 		for _, u := range users {
-			rc.ReportOutput.Row(t.createUserRow(sf, u))
+			accounts.Resolve(sharedFolderAsMember[sfid], u.User.TeamMemberId, u.User.AccountId)
+		}
+		for _, inv := range invitees {
+			if inv.User != nil {
+				accounts.Resolve(sharedFolderAsMember[sfid], inv.User.TeamMemberId, inv.User.AccountId)
+			}
 		}
-		for _, i := range invitees {
-			rc.ReportOutput.Row(t.createInviteeRow(sf, i))
+		if t.EffectiveAccess {
+			for _, g := range groups {
+				if !g.Group.SameTeam {
+					continue
+				}
+				members, err := groupMembers.GroupMembers(g.Group.GroupId)
+				if err != nil {
+					return nil, err
+				}
+				fm.groupMembers[g.Group.GroupId] = members
+			}
 		}
+		return fm, nil
+	}
+	writeFolderMembers := func(i int, folderMembers interface{}, err error) error {
+		sf := sharedFolders[sharedFolderIds[i]]
+		if err != nil {
+			seelog.Warnf("Unable to load shared folder member information for shared folder '%s'", sf.SharedFolderId)
+			return rc.HandleError(sf.SharedFolderId, err)
+		}
+		if folderMembers == nil {
+			return nil
+		}
+		rows := t.createRows(accounts, sf, sharedFolderAsMember[sf.SharedFolderId], folderMembers.(*sharedFolderMembers))
+		for _, row := range rows {
+			if err := rc.ReportOutput.Row(row); err != nil {
+				return err
+			}
+		}
+		rc.Checkpoint.Done("folder-members/" + sf.SharedFolderId)
+		return rc.Checkpoint.Commit()
 	}
 
-	return nil
+	return crawler.ForEachOrdered(len(sharedFolderIds), rc.Concurrency, loadFolderMembers, writeFolderMembers)
+}
+
+// Create rows of the shared folder.
+func (t *ReportSharedFolderMembers) createRows(accounts *accountResolver, sf *sharing.SharedFolderMetadata, asMemberId string, fm *sharedFolderMembers) [][]string {
+	rows := make([][]string, 0)
+	if t.EffectiveAccess {
+		for _, a := range resolveEffectiveAccess(fm) {
+			email, displayName := accounts.Resolve(asMemberId, a.teamMemberId, a.accountId)
+			if a.email == "" {
+				a.email = email
+			}
+			rows = append(rows, t.createEffectiveAccessRow(sf, a, displayName))
+		}
+		return rows
+	}
+	for _, g := range fm.groups {
+		rows = append(rows, t.createGroupRow(sf, g))
+	}
+	for _, u := range fm.users {
+		email, displayName := accounts.Resolve(asMemberId, u.User.TeamMemberId, u.User.AccountId)
+		rows = append(rows, t.createUserRow(sf, u, email, displayName))
+	}
+	for _, i := range fm.invitees {
+		displayName := ""
+		if i.User != nil {
+			_, displayName = accounts.Resolve(asMemberId, i.User.TeamMemberId, i.User.AccountId)
+		}
+		rows = append(rows, t.createInviteeRow(sf, i, displayName))
+	}
+	return rows
 }
 
 func (t *ReportSharedFolderMembers) createHeader() []string {
@@ -94,6 +219,7 @@ func (t *ReportSharedFolderMembers) createHeader() []string {
 		"account-id",
 		"team-member-id",
 		"email",
+		"display-name",
 		"same-team",
 		"group-id",
 		"group-external-id",
@@ -111,6 +237,7 @@ func (t *ReportSharedFolderMembers) createGroupRow(sf *sharing.SharedFolderMetad
 		"", // account-id
 		"", // team-member-id
 		"", // email
+		"", // display-name
 		"", // same-team
 		g.Group.GroupId,
 		g.Group.GroupExternalId,
@@ -118,7 +245,7 @@ func (t *ReportSharedFolderMembers) createGroupRow(sf *sharing.SharedFolderMetad
 	}
 }
 
-func (t *ReportSharedFolderMembers) createUserRow(sf *sharing.SharedFolderMetadata, u *sharing.UserMembershipInfo) []string {
+func (t *ReportSharedFolderMembers) createUserRow(sf *sharing.SharedFolderMetadata, u *sharing.UserMembershipInfo, email, displayName string) []string {
 	return []string{
 		sf.SharedFolderId,
 		sf.Name,
@@ -127,7 +254,8 @@ func (t *ReportSharedFolderMembers) createUserRow(sf *sharing.SharedFolderMetada
 		u.AccessType.Tag,
 		u.User.AccountId,
 		u.User.TeamMemberId,
-		"", // email
+		email,
+		displayName,
 		strconv.FormatBool(u.User.SameTeam),
 		"", // group-id
 		"", // group-external-id
@@ -135,7 +263,7 @@ func (t *ReportSharedFolderMembers) createUserRow(sf *sharing.SharedFolderMetada
 	}
 }
 
-func (t *ReportSharedFolderMembers) createInviteeRow(sf *sharing.SharedFolderMetadata, i *sharing.InviteeMembershipInfo) []string {
+func (t *ReportSharedFolderMembers) createInviteeRow(sf *sharing.SharedFolderMetadata, i *sharing.InviteeMembershipInfo, displayName string) []string {
 	userAccountId := ""
 	userTeamMemberId := ""
 	userSameTeam := ""
@@ -155,9 +283,37 @@ func (t *ReportSharedFolderMembers) createInviteeRow(sf *sharing.SharedFolderMet
 		userAccountId,
 		userTeamMemberId,
 		i.Invitee.Email,
+		displayName,
 		userSameTeam,
 		"", // group-id
 		"", // group-external-id
 		"", // group-name
 	}
-}
\ No newline at end of file
+}
+
+func (t *ReportSharedFolderMembers) createEffectiveAccessRow(sf *sharing.SharedFolderMetadata, a *effectiveAccess, displayName string) []string {
+	groupId := ""
+	groupExternalId := ""
+	groupName := ""
+	if a.group != nil {
+		groupId = a.group.GroupId
+		groupExternalId = a.group.GroupExternalId
+		groupName = a.group.GroupName
+	}
+
+	return []string{
+		sf.SharedFolderId,
+		sf.Name,
+		strconv.FormatBool(sf.IsTeamFolder),
+		a.grantedVia,
+		a.accessLevel,
+		a.accountId,
+		a.teamMemberId,
+		a.email,
+		displayName,
+		a.sameTeam,
+		groupId,
+		groupExternalId,
+		groupName,
+	}
+}
@@ -0,0 +1,182 @@
+package sharing
+
+import (
+	"flag"
+	"github.com/cihub/seelog"
+	"github.com/dropbox/dropbox-sdk-go-unofficial/team"
+	"github.com/watermint/dreport/auth"
+	"github.com/watermint/dreport/crawler"
+	"github.com/watermint/dreport/integration"
+	"strings"
+	"time"
+)
+
+// ReportSharedLinks lists shared links of each member. The API does not provide
+// creation time of shared links, thus the creation time is optionally taken from the
+// latest `shared_link_create` event of the path in the team event log. The creation
+// time is empty if the lookup is not enabled, the audit token is not available, or
+// the event is not found within the period.
+type ReportSharedLinks struct {
+	PublicOnly      bool
+	NonExpiringOnly bool
+	CreatedTime     bool
+}
+
+func (t *ReportSharedLinks) ReportName() string {
+	return "SharedLinks"
+}
+
+func (t *ReportSharedLinks) ReportDescription() string {
+	return "List all shared links of all team members of a team"
+}
+
+func (t *ReportSharedLinks) RequiredPermissions() []string {
+	return []string{
+		auth.PERMISSION_INFO,
+		auth.PERMISSION_FILE,
+	}
+}
+
+func (t *ReportSharedLinks) Resumable() bool {
+	return true
+}
+
+func (t *ReportSharedLinks) OptionalPermissions() []string {
+	return []string{auth.PERMISSION_AUDIT}
+}
+
+func (t *ReportSharedLinks) DefineOptions(f *flag.FlagSet) {
+	f.BoolVar(&t.PublicOnly, "links-public-only", false, "Output only public links of SharedLinks")
+	f.BoolVar(&t.NonExpiringOnly, "links-non-expiring-only", false, "Output only links without expiry of SharedLinks")
+	f.BoolVar(&t.CreatedTime, "links-created-time", false, "Look up creation time of SharedLinks from the team event log within the period of -since and -until")
+}
+
+func (t *ReportSharedLinks) Report(rc *integration.ReportContext) error {
+	members, err := rc.Members.Members()
+	if err != nil {
+		return err
+	}
+
+	// Creation time of links, keyed by team member id and path.
+	created := make(map[string]time.Time)
+	switch {
+	case !t.CreatedTime:
+		// Creation time is not looked up
+	case rc.TeamAuditToken == "":
+		seelog.Info("Audit token is not available. Skip creation time of shared links")
+	default:
+		err = crawler.AllTeamEventsOfType(rc, "shared_link_create", func(e *crawler.TeamEvent) error {
+			if e.Context == nil || e.Context.TeamMemberId == "" {
+				return nil
+			}
+			for _, a := range e.Assets {
+				if a.Path == nil || a.Path.Contextual == "" {
+					continue
+				}
+				key := t.linkKey(e.Context.TeamMemberId, a.Path.Contextual)
+				if c, ok := created[key]; ok && !e.Timestamp.After(c) {
+					continue
+				}
+				created[key] = e.Timestamp
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	rc.ReportOutput.Headers(t.createHeader())
+
+	loadLinks := func(i int) (interface{}, error) {
+		m := members[i]
+		if rc.Checkpoint.IsDone("shared-links/" + m.Profile.TeamMemberId) {
+			return nil, nil
+		}
+		return crawler.AllSharedLinks(rc.TeamFileToken, m.Profile.TeamMemberId)
+	}
+	writeLinks := func(i int, links interface{}, err error) error {
+		m := members[i]
+		if err != nil {
+			return rc.HandleError(m.Profile.TeamMemberId, err)
+		}
+		if links == nil {
+			return nil
+		}
+		for _, l := range links.([]*crawler.SharedLink) {
+			if !t.isTarget(l) {
+				continue
+			}
+			if err := rc.ReportOutput.Row(t.createRow(m, l, created)); err != nil {
+				return err
+			}
+		}
+		rc.Checkpoint.Done("shared-links/" + m.Profile.TeamMemberId)
+		return rc.Checkpoint.Commit()
+	}
+
+	return crawler.ForEachOrdered(len(members), rc.Concurrency, loadLinks, writeLinks)
+}
+
+func (t *ReportSharedLinks) linkKey(teamMemberId, path string) string {
+	return teamMemberId + ":" + strings.ToLower(path)
+}
+
+func (t *ReportSharedLinks) visibility(l *crawler.SharedLink) string {
+	if l.LinkPermissions == nil || l.LinkPermissions.ResolvedVisibility == nil {
+		return ""
+	}
+	return l.LinkPermissions.ResolvedVisibility.Tag
+}
+
+func (t *ReportSharedLinks) isTarget(l *crawler.SharedLink) bool {
+	if t.PublicOnly && t.visibility(l) != "public" {
+		return false
+	}
+	if t.NonExpiringOnly && l.Expires != nil {
+		return false
+	}
+	return true
+}
+
+func (t *ReportSharedLinks) createHeader() []string {
+	return []string{
+		"team-member-id",
+		"owner-email",
+		"link-type",
+		"url",
+		"name",
+		"path",
+		"visibility",
+		"requested-visibility",
+		"expires",
+		"created",
+	}
+}
+
+func (t *ReportSharedLinks) createRow(member *team.TeamMemberInfo, l *crawler.SharedLink, created map[string]time.Time) []string {
+	requestedVisibility := ""
+	if l.LinkPermissions != nil && l.LinkPermissions.RequestedVisibility != nil {
+		requestedVisibility = l.LinkPermissions.RequestedVisibility.Tag
+	}
+	expires := ""
+	if l.Expires != nil {
+		expires = l.Expires.String()
+	}
+	createdAt := ""
+	if c, ok := created[t.linkKey(member.Profile.TeamMemberId, l.PathLower)]; ok {
+		createdAt = c.String()
+	}
+	return []string{
+		member.Profile.TeamMemberId,
+		member.Profile.Email,
+		l.Tag,
+		l.Url,
+		l.Name,
+		l.PathLower,
+		t.visibility(l),
+		requestedVisibility,
+		expires,
+		createdAt,
+	}
+}
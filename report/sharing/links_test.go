@@ -0,0 +1,30 @@
+package sharing
+
+import (
+	"testing"
+	"time"
+
+	"github.com/dropbox/dropbox-sdk-go-unofficial/team"
+	"github.com/watermint/dreport/crawler"
+)
+
+func TestSharedLinksCreated(t *testing.T) {
+	r := &ReportSharedLinks{}
+	member := &team.TeamMemberInfo{Profile: &team.TeamMemberProfile{}}
+	member.Profile.TeamMemberId = "dbmid:1"
+
+	at := time.Date(2017, 1, 2, 3, 4, 5, 0, time.UTC)
+	created := map[string]time.Time{
+		r.linkKey("dbmid:1", "/Shared/Report.pdf"): at,
+	}
+	header := r.createHeader()
+
+	row := r.createRow(member, &crawler.SharedLink{PathLower: "/shared/report.pdf"}, created)
+	if len(row) != len(header) || row[len(row)-1] != at.String() {
+		t.Errorf("Unexpected row: %v", row)
+	}
+	row = r.createRow(member, &crawler.SharedLink{PathLower: "/other.pdf"}, created)
+	if row[len(row)-1] != "" {
+		t.Errorf("Creation time should be empty: %v", row)
+	}
+}
@@ -0,0 +1,260 @@
+package sharing
+
+import (
+	"errors"
+	"flag"
+	"github.com/cihub/seelog"
+	"github.com/watermint/dreport/auth"
+	"github.com/watermint/dreport/crawler"
+	"github.com/watermint/dreport/integration"
+	"strconv"
+	"strings"
+)
+
+type ReportTeamFolders struct {
+	ContentSize bool
+}
+
+type teamFolderDetail struct {
+	members   *sharedFolderMembers
+	size      uint64
+	fileCount uint64
+}
+
+func (t *ReportTeamFolders) ReportName() string {
+	return "TeamFolders"
+}
+
+func (t *ReportTeamFolders) ReportDescription() string {
+	return "List all team folders and their members of a team"
+}
+
+func (t *ReportTeamFolders) RequiredPermissions() []string {
+	return []string{
+		auth.PERMISSION_INFO,
+		auth.PERMISSION_FILE,
+	}
+}
+
+func (t *ReportTeamFolders) Resumable() bool {
+	return true
+}
+
+func (t *ReportTeamFolders) DefineOptions(f *flag.FlagSet) {
+	f.BoolVar(&t.ContentSize, "team-folder-size", false, "Calculate content size of each team folder of TeamFolders (lists all files of team folders)")
+}
+
+// Find an active team admin to access team folders. Team folders are accessed as
+// the admin, regardless of whether the admin is a member of team folders.
+func (t *ReportTeamFolders) findAdmin(rc *integration.ReportContext) (string, error) {
+	members, err := rc.Members.Members()
+	if err != nil {
+		return "", err
+	}
+	for _, m := range members {
+		if m.Role.Tag == "team_admin" && m.Profile.Status.Tag == "active" {
+			return m.Profile.TeamMemberId, nil
+		}
+	}
+	return "", errors.New("No active team admin found to access team folders")
+}
+
+func (t *ReportTeamFolders) Report(rc *integration.ReportContext) error {
+	admin, err := t.findAdmin(rc)
+	if err != nil {
+		return err
+	}
+	teamFolders, err := crawler.AllTeamFolders(rc)
+	if err != nil {
+		return err
+	}
+
+	rc.ReportOutput.Headers(t.createHeader())
+
+	accounts := newAccountResolver(rc)
+	loadDetail := func(i int) (interface{}, error) {
+		tf := teamFolders[i]
+		if rc.Checkpoint.IsDone("team-folders/" + tf.TeamFolderId) {
+			return nil, nil
+		}
+		detail := &teamFolderDetail{
+			members: &sharedFolderMembers{},
+		}
+		if tf.Status == nil || tf.Status.Tag != "active" {
+			// Archived team folders are not accessible
+			return detail, nil
+		}
+
+		groups, users, invitees, err := crawler.AllTeamFolderMembers(rc, admin, tf.TeamFolderId)
+		if err != nil {
+			return nil, err
+		}
+		detail.members.groups = groups
+		detail.members.users = users
+		detail.members.invitees = invitees
+
+		// Resolve accounts in advance, to look up external accounts concurrently
+		for _, u := range users {
+			accounts.Resolve(admin, u.User.TeamMemberId, u.User.AccountId)
+		}
+		for _, inv := range invitees {
+			if inv.User != nil {
+				accounts.Resolve(admin, inv.User.TeamMemberId, inv.User.AccountId)
+			}
+		}
+
+		if t.ContentSize {
+			seelog.Infof("Calculating content size of team folder '%s'", tf.Name)
+			err := crawler.ListTeamFolderPages(rc.TeamFileToken, admin, tf.TeamFolderId, "", func(entries []*crawler.FileEntry, cursor string) error {
+				for _, e := range entries {
+					if e.Tag == "file" {
+						detail.size += e.Size
+						detail.fileCount++
+					}
+				}
+				return nil
+			})
+			if err != nil {
+				return nil, err
+			}
+		}
+		return detail, nil
+	}
+	writeDetail := func(i int, detail interface{}, err error) error {
+		tf := teamFolders[i]
+		if err != nil {
+			seelog.Warnf("Unable to load team folder information for team folder '%s'", tf.TeamFolderId)
+			return rc.HandleError(tf.TeamFolderId, err)
+		}
+		if detail == nil {
+			return nil
+		}
+		rows := t.createRows(accounts, admin, tf, detail.(*teamFolderDetail))
+		for _, row := range rows {
+			if err := rc.ReportOutput.Row(row); err != nil {
+				return err
+			}
+		}
+		rc.Checkpoint.Done("team-folders/" + tf.TeamFolderId)
+		return rc.Checkpoint.Commit()
+	}
+
+	return crawler.ForEachOrdered(len(teamFolders), rc.Concurrency, loadDetail, writeDetail)
+}
+
+// Create rows of the team folder.
+func (t *ReportTeamFolders) createRows(accounts *accountResolver, admin string, tf *crawler.TeamFolder, d *teamFolderDetail) [][]string {
+	rows := make([][]string, 0)
+	if len(d.members.groups)+len(d.members.users)+len(d.members.invitees) < 1 {
+		rows = append(rows, append(t.folderColumns(tf, d),
+			"", // member-type
+			"", // access-level
+			"", // account-id
+			"", // team-member-id
+			"", // email
+			"", // display-name
+			"", // group-id
+			"", // group-name
+		))
+	}
+	for _, g := range d.members.groups {
+		rows = append(rows, append(t.folderColumns(tf, d),
+			"group",
+			g.AccessType.Tag,
+			"", // account-id
+			"", // team-member-id
+			"", // email
+			"", // display-name
+			g.Group.GroupId,
+			g.Group.GroupName,
+		))
+	}
+	for _, u := range d.members.users {
+		email, displayName := accounts.Resolve(admin, u.User.TeamMemberId, u.User.AccountId)
+		rows = append(rows, append(t.folderColumns(tf, d),
+			"user",
+			u.AccessType.Tag,
+			u.User.AccountId,
+			u.User.TeamMemberId,
+			email,
+			displayName,
+			"", // group-id
+			"", // group-name
+		))
+	}
+	for _, inv := range d.members.invitees {
+		accountId := ""
+		teamMemberId := ""
+		displayName := ""
+		if inv.User != nil {
+			accountId = inv.User.AccountId
+			teamMemberId = inv.User.TeamMemberId
+			_, displayName = accounts.Resolve(admin, teamMemberId, accountId)
+		}
+		rows = append(rows, append(t.folderColumns(tf, d),
+			"invitee",
+			inv.AccessType.Tag,
+			accountId,
+			teamMemberId,
+			inv.Invitee.Email,
+			displayName,
+			"", // group-id
+			"", // group-name
+		))
+	}
+	return rows
+}
+
+func (t *ReportTeamFolders) createHeader() []string {
+	return []string{
+		"team-folder-id",
+		"team-folder-name",
+		"status",
+		"is-team-shared-dropbox",
+		"sync-setting",
+		"content-sync-settings",
+		"size",
+		"file-count",
+		"member-type",
+		"access-level",
+		"account-id",
+		"team-member-id",
+		"email",
+		"display-name",
+		"group-id",
+		"group-name",
+	}
+}
+
+func (t *ReportTeamFolders) folderColumns(tf *crawler.TeamFolder, d *teamFolderDetail) []string {
+	status := ""
+	if tf.Status != nil {
+		status = tf.Status.Tag
+	}
+	syncSetting := ""
+	if tf.SyncSetting != nil {
+		syncSetting = tf.SyncSetting.Tag
+	}
+	contentSyncSettings := make([]string, 0, len(tf.ContentSyncSettings))
+	for _, s := range tf.ContentSyncSettings {
+		if s.SyncSetting != nil {
+			contentSyncSettings = append(contentSyncSettings, s.Id+"="+s.SyncSetting.Tag)
+		}
+	}
+	size := ""
+	fileCount := ""
+	if t.ContentSize && status == "active" {
+		size = strconv.FormatUint(d.size, 10)
+		fileCount = strconv.FormatUint(d.fileCount, 10)
+	}
+	return []string{
+		tf.TeamFolderId,
+		tf.Name,
+		status,
+		strconv.FormatBool(tf.IsTeamSharedDropbox),
+		syncSetting,
+		strings.Join(contentSyncSettings, ";"),
+		size,
+		fileCount,
+	}
+}
@@ -0,0 +1,68 @@
+package statistics
+
+import (
+	"github.com/watermint/dreport/auth"
+	"github.com/watermint/dreport/crawler"
+	"github.com/watermint/dreport/integration"
+)
+
+type ReportTeamActivityStats struct {
+}
+
+func (t *ReportTeamActivityStats) ReportName() string {
+	return "TeamActivityStats"
+}
+
+func (t *ReportTeamActivityStats) ReportDescription() string {
+	return "List daily activity statistics of a team"
+}
+
+func (t *ReportTeamActivityStats) RequiredPermissions() []string {
+	return []string{auth.PERMISSION_INFO}
+}
+
+func (t *ReportTeamActivityStats) Report(context *integration.ReportContext) error {
+	stats, err := crawler.TeamActivityStats(context)
+	if err != nil {
+		return err
+	}
+
+	context.ReportOutput.Headers(t.createHeader())
+
+	return writeDailyRows(context, stats.StartDate,
+		stats.Adds,
+		stats.Edits,
+		stats.Deletes,
+		stats.ActiveUsers28Day,
+		stats.ActiveUsers7Day,
+		stats.ActiveUsers1Day,
+		stats.ActiveSharedFolders28Day,
+		stats.ActiveSharedFolders7Day,
+		stats.ActiveSharedFolders1Day,
+		stats.SharedLinksCreated,
+		stats.SharedLinksViewedByTeam,
+		stats.SharedLinksViewedByOutsideUser,
+		stats.SharedLinksViewedByNotLoggedIn,
+		stats.SharedLinksViewedTotal,
+	)
+}
+
+func (t *ReportTeamActivityStats) createHeader() []string {
+	return []string{
+		"date",
+		"adds",
+		"edits",
+		"deletes",
+		"active-users-28day",
+		"active-users-7day",
+		"active-users-1day",
+		"active-shared-folders-28day",
+		"active-shared-folders-7day",
+		"active-shared-folders-1day",
+		"shared-links-created",
+		"shared-links-viewed-by-team",
+		"shared-links-viewed-by-outside-user",
+		"shared-links-viewed-by-not-logged-in",
+		"shared-links-viewed-total",
+	}
+}
@@ -0,0 +1,58 @@
+package statistics
+
+import (
+	"github.com/watermint/dreport/auth"
+	"github.com/watermint/dreport/crawler"
+	"github.com/watermint/dreport/integration"
+)
+
+type ReportTeamDeviceStats struct {
+}
+
+func (t *ReportTeamDeviceStats) ReportName() string {
+	return "TeamDeviceStats"
+}
+
+func (t *ReportTeamDeviceStats) ReportDescription() string {
+	return "List daily active device statistics of a team"
+}
+
+func (t *ReportTeamDeviceStats) RequiredPermissions() []string {
+	return []string{auth.PERMISSION_INFO}
+}
+
+func (t *ReportTeamDeviceStats) Report(context *integration.ReportContext) error {
+	stats, err := crawler.TeamDevicesStats(context)
+	if err != nil {
+		return err
+	}
+
+	context.ReportOutput.Headers(t.createHeader())
+
+	columns := make([][]*uint64, 0)
+	for _, a := range []*crawler.DevicesActive{stats.Active1Day, stats.Active7Day, stats.Active28Day} {
+		if a == nil {
+			a = &crawler.DevicesActive{}
+		}
+		columns = append(columns,
+			a.Windows,
+			a.Macos,
+			a.Linux,
+			a.Ios,
+			a.Android,
+			a.Other,
+			a.Total,
+		)
+	}
+	return writeDailyRows(context, stats.StartDate, columns...)
+}
+
+func (t *ReportTeamDeviceStats) createHeader() []string {
+	header := []string{"date"}
+	for _, period := range []string{"1day", "7day", "28day"} {
+		for _, device := range []string{"windows", "macos", "linux", "ios", "android", "other", "total"} {
+			header = append(header, "active-"+period+"-"+device)
+		}
+	}
+	return header
+}
@@ -0,0 +1,50 @@
+package statistics
+
+import (
+	"github.com/watermint/dreport/auth"
+	"github.com/watermint/dreport/crawler"
+	"github.com/watermint/dreport/integration"
+)
+
+type ReportTeamMembershipStats struct {
+}
+
+func (t *ReportTeamMembershipStats) ReportName() string {
+	return "TeamMembershipStats"
+}
+
+func (t *ReportTeamMembershipStats) ReportDescription() string {
+	return "List daily membership statistics of a team"
+}
+
+func (t *ReportTeamMembershipStats) RequiredPermissions() []string {
+	return []string{auth.PERMISSION_INFO}
+}
+
+func (t *ReportTeamMembershipStats) Report(context *integration.ReportContext) error {
+	stats, err := crawler.TeamMembershipStats(context)
+	if err != nil {
+		return err
+	}
+
+	context.ReportOutput.Headers(t.createHeader())
+
+	return writeDailyRows(context, stats.StartDate,
+		stats.TeamSize,
+		stats.PendingInvites,
+		stats.MembersJoined,
+		stats.SuspendedMembers,
+		stats.Licenses,
+	)
+}
+
+func (t *ReportTeamMembershipStats) createHeader() []string {
+	return []string{
+		"date",
+		"team-size",
+		"pending-invites",
+		"members-joined",
+		"suspended-members",
+		"licenses",
+	}
+}
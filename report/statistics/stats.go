@@ -0,0 +1,42 @@
+package statistics
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/watermint/dreport/crawler"
+	"github.com/watermint/dreport/integration"
+)
+
+// Write one row per day. Each column is an array of daily values from the start date.
+func writeDailyRows(rc *integration.ReportContext, startDate string, columns ...[]*uint64) error {
+	start, err := time.Parse(crawler.StatsDateLayout, startDate)
+	if err != nil {
+		return err
+	}
+
+	days := 0
+	for _, c := range columns {
+		if len(c) > days {
+			days = len(c)
+		}
+	}
+
+	for d := 0; d < days; d++ {
+		row := []string{start.AddDate(0, 0, d).Format(crawler.StatsDateLayout)}
+		for _, c := range columns {
+			row = append(row, dailyValue(c, d))
+		}
+		if err := rc.ReportOutput.Row(row); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func dailyValue(values []*uint64, day int) string {
+	if day >= len(values) || values[day] == nil {
+		return ""
+	}
+	return strconv.FormatUint(*values[day], 10)
+}
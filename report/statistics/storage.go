@@ -0,0 +1,48 @@
+package statistics
+
+import (
+	"github.com/watermint/dreport/auth"
+	"github.com/watermint/dreport/crawler"
+	"github.com/watermint/dreport/integration"
+)
+
+type ReportTeamStorageStats struct {
+}
+
+func (t *ReportTeamStorageStats) ReportName() string {
+	return "TeamStorageStats"
+}
+
+func (t *ReportTeamStorageStats) ReportDescription() string {
+	return "List daily storage statistics of a team"
+}
+
+func (t *ReportTeamStorageStats) RequiredPermissions() []string {
+	return []string{auth.PERMISSION_INFO}
+}
+
+func (t *ReportTeamStorageStats) Report(context *integration.ReportContext) error {
+	stats, err := crawler.TeamStorageStats(context)
+	if err != nil {
+		return err
+	}
+
+	context.ReportOutput.Headers(t.createHeader())
+
+	return writeDailyRows(context, stats.StartDate,
+		stats.TotalUsage,
+		stats.SharedUsage,
+		stats.UnsharedUsage,
+		stats.SharedFolders,
+	)
+}
+
+func (t *ReportTeamStorageStats) createHeader() []string {
+	return []string{
+		"date",
+		"total-usage",
+		"shared-usage",
+		"unshared-usage",
+		"shared-folders",
+	}
+}